@@ -0,0 +1,162 @@
+package highwinds
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Errors and string checks
+const (
+	ErrTooManyPages = "listing the sub-accounts of %s did not finish within %d pages"
+)
+
+const (
+	// subAccountPageSize is how many sub-accounts are requested per page
+	subAccountPageSize = 100
+
+	// subAccountMaxPages bounds a single listing
+	subAccountMaxPages = 100
+)
+
+// Account is a StrikeTracker account as returned by the accounts API
+type Account struct {
+	ID                int    `json:"id"`
+	AccountHash       string `json:"accountHash"`
+	AccountName       string `json:"accountName"`
+	AccountStatus     string `json:"accountStatus"`
+	ParentAccountHash string `json:"parentAccountHash"`
+	CreatedDate       string `json:"createdDate"`
+	UpdatedDate       string `json:"updatedDate"`
+}
+
+// accountList is one page of a sub-account listing
+type accountList struct {
+	List []*Account `json:"list"`
+}
+
+// getAccount reads a single account
+func (m *Meta) getAccount(ctx context.Context, accountHash string) (*Account, error) {
+	account := &Account{}
+	if err := m.apiGet(ctx, fmt.Sprintf("/api/v1/accounts/%s", accountHash), nil, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// listSubAccounts returns the direct children of an account, following pages.
+// Paging stops on a short or empty page, or on a page of accounts already
+// seen in case the API ignores the paging parameters
+func (m *Meta) listSubAccounts(ctx context.Context, accountHash string) ([]*Account, error) {
+	var accounts []*Account
+	seen := map[string]bool{}
+	for page := 1; page <= subAccountMaxPages; page++ {
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("size", strconv.Itoa(subAccountPageSize))
+
+		result := &accountList{}
+		if err := m.apiGet(ctx, fmt.Sprintf("/api/v1/accounts/%s/subaccounts", accountHash), query, result); err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, account := range result.List {
+			if seen[account.AccountHash] {
+				continue
+			}
+			seen[account.AccountHash] = true
+			accounts = append(accounts, account)
+			added++
+		}
+
+		if added == 0 || len(result.List) < subAccountPageSize {
+			return accounts, nil
+		}
+	}
+
+	return nil, fmt.Errorf(ErrTooManyPages, accountHash, subAccountMaxPages)
+}
+
+// walkSubAccounts calls fn for every sub-account below accountHash, breadth
+// first, with the hash it was listed under and depth 1 for direct children.
+// Only direct children are visited unless recursive is set. Returning false
+// from fn stops the walk
+func (m *Meta) walkSubAccounts(ctx context.Context, accountHash string, recursive bool, fn func(account *Account, parentHash string, depth int) bool) error {
+	type level struct {
+		hash  string
+		depth int
+	}
+	queue := []level{{hash: accountHash}}
+	seen := map[string]bool{accountHash: true}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		children, err := m.listSubAccounts(ctx, current.hash)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if seen[child.AccountHash] {
+				continue
+			}
+			seen[child.AccountHash] = true
+
+			if !fn(child, current.hash, current.depth+1) {
+				return nil
+			}
+			if recursive {
+				queue = append(queue, level{hash: child.AccountHash, depth: current.depth + 1})
+			}
+		}
+	}
+
+	return nil
+}
+
+// findSubAccount looks for childHash anywhere below parentHash
+func (m *Meta) findSubAccount(ctx context.Context, parentHash string, childHash string) (*Account, error) {
+	var found *Account
+	err := m.walkSubAccounts(ctx, parentHash, true, func(account *Account, _ string, _ int) bool {
+		if account.AccountHash == childHash {
+			found = account
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+// findAccountByName returns the single account named name, looking at
+// rootHash and everything below it
+func (m *Meta) findAccountByName(ctx context.Context, rootHash string, name string) (*Account, error) {
+	root, err := m.getAccount(ctx, rootHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Account
+	if root.AccountName == name {
+		matches = append(matches, root)
+	}
+	err = m.walkSubAccounts(ctx, rootHash, true, func(account *Account, _ string, _ int) bool {
+		if account.AccountName == name {
+			matches = append(matches, account)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf(ErrNoMatches, "accounts", rootHash, fmt.Sprintf("name %q", name))
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf(ErrMultipleMatches, len(matches), "accounts", rootHash, fmt.Sprintf("name %q", name))
+}
@@ -0,0 +1,174 @@
+package highwinds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Errors and string checks
+const (
+	ErrAuditLogOpen = "unable to open audit_log_path %s: %v"
+)
+
+// auditEntry is one line of the audit journal
+type auditEntry struct {
+	Timestamp         string        `json:"timestamp"`
+	User              string        `json:"user,omitempty"`
+	ParentAccountHash string        `json:"parent_account_hash,omitempty"`
+	Action            string        `json:"action"`
+	ResourceType      string        `json:"resource_type"`
+	AccountHash       string        `json:"account_hash"`
+	HostHash          string        `json:"host_hash,omitempty"`
+	ScopeID           string        `json:"scope_id,omitempty"`
+	ResourceID        string        `json:"resource_id,omitempty"`
+	Model             interface{}   `json:"model,omitempty"`
+	Diff              []auditChange `json:"diff,omitempty"`
+	Status            int           `json:"status"`
+	Error             string        `json:"error,omitempty"`
+}
+
+// auditChange is a single field that differs from the previously read model
+type auditChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// auditLog appends JSON lines to a local journal file
+type auditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// newAuditLog checks the journal can be written to before anything is changed
+func newAuditLog(path string) (*auditLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf(ErrAuditLogOpen, path, err)
+	}
+	f.Close()
+
+	return &auditLog{path: path}, nil
+}
+
+// write appends a single entry to the journal
+func (a *auditLog) write(entry *auditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// auditEnabled reports whether mutating calls are being journaled
+func (m *Meta) auditEnabled() bool {
+	return m.auditLog != nil
+}
+
+// auditCall records a mutating API call made with a context from withStatusRecorder
+func (m *Meta) auditCall(ctx context.Context, entry *auditEntry, err error) {
+	if !m.auditEnabled() {
+		return
+	}
+
+	entry.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	if m.User != nil {
+		entry.User = m.User.Username
+	}
+	entry.ParentAccountHash = m.ParentAccountHash
+	entry.Model = redactModel(entry.Model)
+	entry.Status = recordedStatus(ctx)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if writeErr := m.auditLog.write(entry); writeErr != nil {
+		log.Printf("[ERROR] Unable to write audit entry for %s %s: %v", entry.Action, entry.ResourceType, writeErr)
+	}
+}
+
+// redactModel converts a model to generic JSON with secret fields redacted
+func redactModel(model interface{}) interface{} {
+	if model == nil || (reflect.ValueOf(model).Kind() == reflect.Ptr && reflect.ValueOf(model).IsNil()) {
+		return nil
+	}
+
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil
+	}
+	return redactValue(decoded)
+}
+
+// diffModels returns the fields that differ between two models, secrets redacted
+func diffModels(previous interface{}, next interface{}) []auditChange {
+	before := map[string]interface{}{}
+	after := map[string]interface{}{}
+	flattenJSON("", redactModel(previous), before)
+	flattenJSON("", redactModel(next), after)
+
+	fields := map[string]bool{}
+	for k := range before {
+		fields[k] = true
+	}
+	for k := range after {
+		fields[k] = true
+	}
+
+	changes := []auditChange{}
+	for field := range fields {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			changes = append(changes, auditChange{Field: field, Old: before[field], New: after[field]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+
+	return changes
+}
+
+// flattenJSON flattens decoded JSON into dotted field paths
+func flattenJSON(prefix string, value interface{}, out map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, inner := range v {
+			flattenJSON(joinField(prefix, k), inner, out)
+		}
+	case []interface{}:
+		for i, inner := range v {
+			flattenJSON(joinField(prefix, fmt.Sprintf("%d", i)), inner, out)
+		}
+	default:
+		if prefix != "" {
+			out[prefix] = v
+		}
+	}
+}
+
+func joinField(prefix string, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
@@ -0,0 +1,230 @@
+package highwinds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors and string checks
+const (
+	ErrNoCredentials   = "no credentials supplied, set authorization_header_key or username/password"
+	ErrMissingPassword = "username %s supplied without a password"
+	ErrTokenGrant      = "unable to obtain an access token from %s (%d): %s"
+)
+
+const (
+	// authTokenPath is the StrikeTracker OAuth token endpoint
+	authTokenPath = "/auth/token"
+
+	// tokenExpirySkew refreshes tokens a little before they actually expire
+	// so an in-flight request is never sent with a token that is about to die
+	tokenExpirySkew = 60 * time.Second
+)
+
+// tokenSource hands out the bearer token used on every API request
+type tokenSource interface {
+	// Token returns a currently valid access token
+	Token(ctx context.Context) (string, error)
+	// Invalidate discards the given token if it is still the current one
+	Invalidate(token string)
+}
+
+// staticTokenSource is a long lived application token
+type staticTokenSource string
+
+func (s staticTokenSource) Token(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+func (s staticTokenSource) Invalidate(token string) {}
+
+// tokenResponse is the body returned by the auth endpoint
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+}
+
+// passwordTokenSource exchanges a username and password for a bearer token
+// and refreshes it as it expires
+type passwordTokenSource struct {
+	mu sync.Mutex
+
+	client        *http.Client
+	tokenURL      string
+	applicationID string
+	username      string
+	password      string
+
+	accessToken  string
+	refreshToken string
+	expiry       time.Time
+}
+
+func newPasswordTokenSource(client *http.Client, endpoint string, applicationID string, username string, password string) *passwordTokenSource {
+	return &passwordTokenSource{
+		client:        client,
+		tokenURL:      strings.TrimRight(endpoint, "/") + authTokenPath,
+		applicationID: applicationID,
+		username:      username,
+		password:      password,
+	}
+}
+
+// Token returns the cached token, refreshing or re-authenticating when it
+// has expired
+func (s *passwordTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.valid() {
+		return s.accessToken, nil
+	}
+
+	if s.refreshToken != "" {
+		err := s.grant(ctx, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {s.refreshToken},
+		})
+		if err == nil {
+			return s.accessToken, nil
+		}
+		log.Printf("[DEBUG] Refreshing access token for %s failed, falling back to password grant: %v", s.username, err)
+	}
+
+	err := s.grant(ctx, url.Values{
+		"grant_type": {"password"},
+		"username":   {s.username},
+		"password":   {s.password},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return s.accessToken, nil
+}
+
+// Invalidate forces the next call to Token to fetch a new token
+func (s *passwordTokenSource) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken == token {
+		s.accessToken = ""
+		s.expiry = time.Time{}
+	}
+}
+
+// valid reports whether the cached token can still be used, must hold mu
+func (s *passwordTokenSource) valid() bool {
+	if s.accessToken == "" {
+		return false
+	}
+	return s.expiry.IsZero() || time.Now().Add(tokenExpirySkew).Before(s.expiry)
+}
+
+// grant posts the given grant to the auth endpoint and stores the result, must hold mu
+func (s *passwordTokenSource) grant(ctx context.Context, form url.Values) error {
+	req, err := http.NewRequest(http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.applicationID != "" {
+		req.Header.Set("X-Application-Id", s.applicationID)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(ErrTokenGrant, s.tokenURL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	token := &tokenResponse{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return err
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf(ErrTokenGrant, s.tokenURL, resp.StatusCode, "response did not include an access token")
+	}
+
+	s.accessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+	s.expiry = time.Time{}
+	if token.ExpiresIn > 0 {
+		s.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	return nil
+}
+
+// authTransport sets the bearer token on every request and retries once
+// with a fresh token when the API rejects an expired one
+type authTransport struct {
+	next   http.RoundTripper
+	tokens tokenSource
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.tokens.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(withBearerToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// Only retry when the body can be replayed and we actually got a new token
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	t.tokens.Invalidate(token)
+	fresh, err := t.tokens.Token(req.Context())
+	if err != nil || fresh == token {
+		return resp, nil
+	}
+
+	retry := withBearerToken(req, fresh)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+
+	resp.Body.Close()
+	log.Printf("[DEBUG] Access token rejected, retrying %s %s with a refreshed token", req.Method, req.URL.Path)
+	return t.next.RoundTrip(retry)
+}
+
+// withBearerToken returns a copy of req carrying the given token
+func withBearerToken(req *http.Request, token string) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
@@ -0,0 +1,91 @@
+package highwinds
+
+import (
+	"context"
+	"sync"
+
+	"github.com/openwurl/wurlwind/striketracker/models"
+	"github.com/openwurl/wurlwind/striketracker/services/hosts"
+)
+
+// hostCache is a read-through cache of host GET responses keyed by
+// account/host, it lives as long as the provider instance
+type hostCache struct {
+	mu      sync.Mutex
+	entries map[string]*hostCacheEntry
+}
+
+// hostCacheEntry is a cached or in-flight host lookup
+type hostCacheEntry struct {
+	ready chan struct{}
+	host  *models.Host
+	err   error
+}
+
+func newHostCache() *hostCache {
+	return &hostCache{
+		entries: map[string]*hostCacheEntry{},
+	}
+}
+
+func hostCacheKey(accountHash string, hostHash string) string {
+	return accountHash + "/" + hostHash
+}
+
+// get returns the cached host, calling fetch once on a miss. Concurrent
+// callers for the same host share a single request
+func (c *hostCache) get(ctx context.Context, accountHash string, hostHash string, fetch func() (*models.Host, error)) (*models.Host, error) {
+	key := hostCacheKey(accountHash, hostHash)
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-entry.ready:
+			return entry.host, entry.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	entry := &hostCacheEntry{ready: make(chan struct{})}
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	entry.host, entry.err = fetch()
+	close(entry.ready)
+
+	// Never keep failures or missing hosts around
+	if entry.err != nil || entry.host == nil {
+		c.mu.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+	}
+
+	return entry.host, entry.err
+}
+
+// invalidate drops the cached host after a write to it
+func (c *hostCache) invalidate(accountHash string, hostHash string) {
+	c.mu.Lock()
+	delete(c.entries, hostCacheKey(accountHash, hostHash))
+	c.mu.Unlock()
+}
+
+// getHost fetches a host through the provider host cache
+func (m *Meta) getHost(ctx context.Context, accountHash string, hostHash string) (*models.Host, error) {
+	return m.hosts.get(ctx, accountHash, hostHash, func() (*models.Host, error) {
+		ctx := withStatusRecorder(ctx)
+		host, err := hosts.New(m.Client).Get(ctx, accountHash, hostHash)
+		if err := lookupError(ctx, err, host != nil, "host", hostHash); err != nil {
+			return nil, err
+		}
+		return host, nil
+	})
+}
+
+// invalidateHost drops a host from the cache after it has been written to
+func (m *Meta) invalidateHost(accountHash string, hostHash string) {
+	m.hosts.invalidate(accountHash, hostHash)
+}
@@ -0,0 +1,183 @@
+package highwinds
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Errors and string checks
+const (
+	ErrBadChangeWindow     = "change_window %s %q is invalid: %v"
+	ErrOutsideChangeWindow = "refusing to %s %s at %s: outside the change_window (days %q, hours %q, %s), set %s=1 for an emergency change"
+)
+
+// EmergencyEnv overrides the change window for a single run
+const EmergencyEnv = "TF_STRIKETRACKER_EMERGENCY"
+
+// weekdayNames maps cron day names onto time.Weekday, 7 is also Sunday and
+// sun stands for 7 at the end of a range
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// changeWindow is the set of days and hours in which hosts and
+// configuration scopes may be changed
+type changeWindow struct {
+	Days     string
+	Hours    string
+	Location *time.Location
+
+	days  map[int]bool
+	hours map[int]bool
+}
+
+// newChangeWindow parses cron style day of week and hour fields such as
+// "mon-fri" and "22,23,0-5" in the given IANA timezone
+func newChangeWindow(days string, hours string, timezone string) (*changeWindow, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf(ErrBadChangeWindow, "timezone", timezone, err)
+	}
+
+	daySet, err := parseCronField(days, 0, 7, weekdayNames)
+	if err != nil {
+		return nil, fmt.Errorf(ErrBadChangeWindow, "days", days, err)
+	}
+	if daySet[7] {
+		daySet[0] = true
+	}
+
+	hourSet, err := parseCronField(hours, 0, 23, nil)
+	if err != nil {
+		return nil, fmt.Errorf(ErrBadChangeWindow, "hours", hours, err)
+	}
+
+	return &changeWindow{
+		Days:     days,
+		Hours:    hours,
+		Location: location,
+		days:     daySet,
+		hours:    hourSet,
+	}, nil
+}
+
+// contains reports whether t falls inside the window
+func (w *changeWindow) contains(t time.Time) bool {
+	local := t.In(w.Location)
+	return w.days[int(local.Weekday())] && w.hours[local.Hour()]
+}
+
+// parseCronField expands a cron field of *, single values, ranges and
+// comma separated lists of both
+func parseCronField(field string, min int, max int, names map[string]int) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		part = strings.TrimSpace(part)
+		if part == "*" {
+			for v := min; v <= max; v++ {
+				values[v] = true
+			}
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		low, err := parseCronValue(bounds[0], min, max, names)
+		if err != nil {
+			return nil, err
+		}
+		high := low
+		if len(bounds) == 2 {
+			if high, err = parseCronValue(bounds[1], min, max, names); err != nil {
+				return nil, err
+			}
+			// sun is 0, but closing a range such as sat-sun it means 7
+			if _, named := names[strings.ToLower(strings.TrimSpace(bounds[1]))]; named && high == 0 && max == 7 {
+				high = 7
+			}
+		}
+		if high < low {
+			return nil, fmt.Errorf("range %s runs backwards, split it into two ranges", part)
+		}
+
+		for v := low; v <= high; v++ {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func parseCronValue(value string, min int, max int, names map[string]int) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if v, ok := names[value]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number or a known name", value)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("%d is outside %d-%d", v, min, max)
+	}
+	return v, nil
+}
+
+// emergencyChange reports whether the change window is overridden for this run
+func emergencyChange() bool {
+	return os.Getenv(EmergencyEnv) == "1"
+}
+
+// checkChangeWindow refuses to change hosts and configuration scopes outside
+// the provider change_window unless the emergency override is set
+func checkChangeWindow(m interface{}, action string, resourceType string) error {
+	window := m.(*Meta).ChangeWindow
+	if window == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if window.contains(now) {
+		return nil
+	}
+	if emergencyChange() {
+		log.Printf("[WARN] %s=1, allowing %s of %s outside the change_window", EmergencyEnv, action, resourceType)
+		return nil
+	}
+
+	return fmt.Errorf(ErrOutsideChangeWindow, action, resourceType, now.In(window.Location).Format(time.RFC1123), window.Days, window.Hours, window.Location, EmergencyEnv)
+}
+
+// warnOutsideChangeWindow logs a warning during plan when a create or update of the
+// resource would be refused if applied now. Destroys never reach CustomizeDiff
+func warnOutsideChangeWindow(resourceType string) schema.CustomizeDiffFunc {
+	return func(d *schema.ResourceDiff, m interface{}) error {
+		meta, ok := m.(*Meta)
+		if !ok || meta.ChangeWindow == nil {
+			return nil
+		}
+		if d.Id() != "" && !hasRemoteChanges(d) {
+			return nil
+		}
+
+		if err := checkChangeWindow(meta, "change", resourceType); err != nil {
+			log.Printf("[WARN] %s %s: an apply started now would fail: %v", resourceType, d.Id(), err)
+		}
+		return nil
+	}
+}
+
+// hasRemoteChanges reports whether a diff touches anything besides local only arguments
+func hasRemoteChanges(d *schema.ResourceDiff) bool {
+	for _, key := range d.GetChangedKeysPrefix("") {
+		if !localOnlyKeys[strings.SplitN(key, ".", 2)[0]] {
+			return true
+		}
+	}
+	return false
+}
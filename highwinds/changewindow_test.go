@@ -0,0 +1,86 @@
+package highwinds
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	cases := []struct {
+		field   string
+		min     int
+		max     int
+		names   map[string]int
+		want    []int
+		wantErr bool
+	}{
+		{field: "*", min: 0, max: 7, names: weekdayNames, want: []int{0, 1, 2, 3, 4, 5, 6, 7}},
+		{field: "mon-fri", min: 0, max: 7, names: weekdayNames, want: []int{1, 2, 3, 4, 5}},
+		{field: "sat-sun", min: 0, max: 7, names: weekdayNames, want: []int{6, 7}},
+		{field: "mon-sun", min: 0, max: 7, names: weekdayNames, want: []int{1, 2, 3, 4, 5, 6, 7}},
+		{field: "sun", min: 0, max: 7, names: weekdayNames, want: []int{0}},
+		{field: "1-7", min: 0, max: 7, names: weekdayNames, want: []int{1, 2, 3, 4, 5, 6, 7}},
+		{field: "Mon, wed ,FRI", min: 0, max: 7, names: weekdayNames, want: []int{1, 3, 5}},
+		{field: "fri-mon", min: 0, max: 7, names: weekdayNames, wantErr: true},
+		{field: "funday", min: 0, max: 7, names: weekdayNames, wantErr: true},
+		{field: "22,23,0-5", min: 0, max: 23, want: []int{0, 1, 2, 3, 4, 5, 22, 23}},
+		{field: "9", min: 0, max: 23, want: []int{9}},
+		{field: "22-5", min: 0, max: 23, wantErr: true},
+		{field: "24", min: 0, max: 23, wantErr: true},
+		{field: "", min: 0, max: 23, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		got, err := parseCronField(tc.field, tc.min, tc.max, tc.names)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseCronField(%q): expected an error, got %v", tc.field, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseCronField(%q): unexpected error: %v", tc.field, err)
+			continue
+		}
+
+		want := map[int]bool{}
+		for _, v := range tc.want {
+			want[v] = true
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parseCronField(%q) = %v, want %v", tc.field, got, want)
+		}
+	}
+}
+
+func TestChangeWindowContains(t *testing.T) {
+	// 2024-06-01 is a Saturday
+	cases := []struct {
+		days     string
+		hours    string
+		timezone string
+		at       time.Time
+		want     bool
+	}{
+		{days: "sat-sun", hours: "*", timezone: "UTC", at: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), want: true},
+		{days: "sat-sun", hours: "*", timezone: "UTC", at: time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC), want: true},
+		{days: "sat-sun", hours: "*", timezone: "UTC", at: time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC), want: false},
+		{days: "mon-sun", hours: "*", timezone: "UTC", at: time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC), want: true},
+		{days: "mon-fri", hours: "22-23,0-4", timezone: "UTC", at: time.Date(2024, 6, 3, 23, 30, 0, 0, time.UTC), want: true},
+		{days: "mon-fri", hours: "22-23,0-4", timezone: "UTC", at: time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC), want: false},
+		{days: "*", hours: "9-17", timezone: "America/New_York", at: time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC), want: true},
+		{days: "*", hours: "9-17", timezone: "America/New_York", at: time.Date(2024, 6, 3, 23, 0, 0, 0, time.UTC), want: false},
+		{days: "mon", hours: "*", timezone: "Asia/Tokyo", at: time.Date(2024, 6, 2, 20, 0, 0, 0, time.UTC), want: true},
+	}
+
+	for _, tc := range cases {
+		window, err := newChangeWindow(tc.days, tc.hours, tc.timezone)
+		if err != nil {
+			t.Fatalf("newChangeWindow(%q, %q, %q): %v", tc.days, tc.hours, tc.timezone, err)
+		}
+		if got := window.contains(tc.at); got != tc.want {
+			t.Errorf("window %q %q %s contains %s = %t, want %t", tc.days, tc.hours, tc.timezone, tc.at, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package highwinds
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/openwurl/wurlwind/striketracker"
+)
+
+// DefaultAPIEndpoint is the production StrikeTracker API
+const DefaultAPIEndpoint = "https://striketracker.highwinds.com"
+
+// Errors and string checks
+const (
+	ErrBadAPIEndpoint = "api_endpoint %s is not a valid URL: %v"
+	ErrBadProxyURL    = "proxy_url %s is not a valid URL: %v"
+	ErrBadCACert      = "ca_cert_pem does not contain any valid PEM encoded certificates"
+)
+
+// Config holds the provider settings used to build the striketracker client
+type Config struct {
+	AuthorizationHeaderKey string
+	ApplicationID          string
+	Username               string
+	Password               string
+	APIEndpoint            string
+	CACertPEM              string
+	InsecureSkipVerify     bool
+	ProxyURL               string
+	AccountHash            string
+	SubAccountHash         string
+	RequestTimeout         time.Duration
+	MaxRetries             int
+	RetryMaxWait           time.Duration
+	MaxRequestsPerSecond   float64
+	Burst                  int
+	ReadOnly               bool
+	AuditLogPath           string
+	PreventDestroyTypes    []string
+	ConsistencyInterval    time.Duration
+	ConsistencyTimeout     time.Duration
+	ChangeWindow           *changeWindow
+}
+
+// configFromResourceData reads the provider block into a Config
+func configFromResourceData(d *schema.ResourceData) (*Config, error) {
+	requestTimeout, err := time.ParseDuration(d.Get("request_timeout").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	retryMaxWait, err := time.ParseDuration(d.Get("retry_max_wait").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	consistencyInterval, err := time.ParseDuration(d.Get("consistency_poll_interval").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	consistencyTimeout, err := time.ParseDuration(d.Get("consistency_timeout").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{
+		AuthorizationHeaderKey: d.Get("authorization_header_key").(string),
+		ApplicationID:          d.Get("application_id").(string),
+		Username:               d.Get("username").(string),
+		Password:               d.Get("password").(string),
+		APIEndpoint:            d.Get("api_endpoint").(string),
+		CACertPEM:              d.Get("ca_cert_pem").(string),
+		InsecureSkipVerify:     d.Get("insecure_skip_verify").(bool),
+		ProxyURL:               d.Get("proxy_url").(string),
+		AccountHash:            d.Get("account_hash").(string),
+		SubAccountHash:         d.Get("sub_account_hash").(string),
+		RequestTimeout:         requestTimeout,
+		MaxRetries:             d.Get("max_retries").(int),
+		RetryMaxWait:           retryMaxWait,
+		MaxRequestsPerSecond:   d.Get("max_requests_per_second").(float64),
+		Burst:                  d.Get("burst").(int),
+		ReadOnly:               d.Get("read_only").(bool),
+		AuditLogPath:           d.Get("audit_log_path").(string),
+		ConsistencyInterval:    consistencyInterval,
+		ConsistencyTimeout:     consistencyTimeout,
+	}
+
+	for _, resourceType := range d.Get("prevent_destroy_types").([]interface{}) {
+		config.PreventDestroyTypes = append(config.PreventDestroyTypes, resourceType.(string))
+	}
+
+	if windows := d.Get("change_window").([]interface{}); len(windows) > 0 && windows[0] != nil {
+		window := windows[0].(map[string]interface{})
+		config.ChangeWindow, err = newChangeWindow(window["days"].(string), window["hours"].(string), window["timezone"].(string))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// HCL and environment settings win over the credentials file
+	if err := config.applyCredentialsFile(d.Get("credentials_file").(string), d.Get("profile").(string)); err != nil {
+		return nil, err
+	}
+	setIfEmpty(&config.ApplicationID, DefaultApplicationID)
+
+	return config, nil
+}
+
+// Meta builds the provider meta handed to resources, every API call made
+// through it is cancelled along with stopCtx
+func (c *Config) Meta(stopCtx context.Context) (*Meta, error) {
+	endpoint, err := c.endpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, token, err := c.httpClient(stopCtx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := striketracker.NewClientWithOptions(
+		striketracker.WithAuthorizationHeaderToken(token),
+		striketracker.WithApplicationID(c.ApplicationID),
+		striketracker.WithHTTPClient(httpClient),
+		striketracker.WithBaseURL(endpoint),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	preventDestroy := map[string]bool{}
+	for _, resourceType := range c.PreventDestroyTypes {
+		if _, ok := Provider().ResourcesMap[resourceType]; !ok {
+			return nil, fmt.Errorf(ErrUnknownType, resourceType)
+		}
+		preventDestroy[resourceType] = true
+	}
+
+	meta := &Meta{
+		Client:              client,
+		AccountHash:         c.AccountHash,
+		ReadOnly:            c.ReadOnly,
+		PreventDestroyTypes: preventDestroy,
+		ChangeWindow:        c.ChangeWindow,
+		endpoint:            endpoint,
+		applicationID:       c.ApplicationID,
+		httpClient:          httpClient,
+		hosts:               newHostCache(),
+		origins:             newListSnapshot(),
+		certificates:        newListSnapshot(),
+		subAccountHash:      c.SubAccountHash,
+		stopCtx:             stopCtx,
+		consistencyInterval: c.ConsistencyInterval,
+		consistencyTimeout:  c.ConsistencyTimeout,
+	}
+
+	if c.AuditLogPath != "" {
+		meta.auditLog, err = newAuditLog(c.AuditLogPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Fail fast on bad credentials instead of halfway through a plan
+	ctx, cancel := context.WithTimeout(stopCtx, c.RequestTimeout)
+	defer cancel()
+	if err := meta.discoverIdentity(ctx); err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// httpClient builds the authenticated http client shared by the striketracker
+// client and direct API calls, returning the initial access token
+func (c *Config) httpClient(stopCtx context.Context, endpoint string) (*http.Client, string, error) {
+	transport, err := c.transport()
+	if err != nil {
+		return nil, "", err
+	}
+	wire := &loggingTransport{next: transport}
+	baseClient := &http.Client{
+		Transport: wire,
+		Timeout:   c.RequestTimeout,
+	}
+
+	tokens, err := c.tokenSource(baseClient, endpoint)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Authenticate up front so bad credentials fail during configure
+	token, err := tokens.Token(stopCtx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &http.Client{
+		Transport: c.apiTransport(wire, tokens),
+		Timeout:   c.RequestTimeout,
+	}, token, nil
+}
+
+// apiTransport layers authentication, rate limiting, retries, the read only
+// guard and request correlation over the base transport, the outermost
+// layer runs first
+func (c *Config) apiTransport(base http.RoundTripper, tokens tokenSource) http.RoundTripper {
+	var rt http.RoundTripper = &authTransport{
+		next:   base,
+		tokens: tokens,
+	}
+
+	if c.MaxRequestsPerSecond > 0 {
+		rt = &rateLimitTransport{
+			next:    rt,
+			limiter: newRateLimiter(c.MaxRequestsPerSecond, c.Burst),
+		}
+	}
+
+	rt = &retryTransport{
+		next:       rt,
+		maxRetries: c.MaxRetries,
+		maxWait:    c.RetryMaxWait,
+	}
+
+	if c.ReadOnly {
+		rt = &readOnlyTransport{next: rt}
+	}
+
+	rt = &statusTransport{next: rt}
+
+	return &requestIDTransport{next: rt}
+}
+
+// endpoint returns the normalized API endpoint
+func (c *Config) endpoint() (string, error) {
+	endpoint := c.APIEndpoint
+	if endpoint == "" {
+		endpoint = DefaultAPIEndpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf(ErrBadAPIEndpoint, endpoint, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf(ErrBadAPIEndpoint, endpoint, "scheme and host are required")
+	}
+
+	return strings.TrimRight(endpoint, "/"), nil
+}
+
+// transport builds the base http transport with the configured TLS and proxy settings
+func (c *Config) transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(c.CACertPEM)) {
+			return nil, fmt.Errorf(ErrBadCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if c.ProxyURL != "" {
+		proxy, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf(ErrBadProxyURL, c.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return transport, nil
+}
+
+// tokenSource picks the credential flow from the configured settings
+func (c *Config) tokenSource(client *http.Client, endpoint string) (tokenSource, error) {
+	switch {
+	case c.Username != "":
+		if c.Password == "" {
+			return nil, fmt.Errorf(ErrMissingPassword, c.Username)
+		}
+		return newPasswordTokenSource(client, endpoint, c.ApplicationID, c.Username, c.Password), nil
+	case c.AuthorizationHeaderKey != "":
+		return staticTokenSource(c.AuthorizationHeaderKey), nil
+	}
+	return nil, fmt.Errorf(ErrNoCredentials)
+}
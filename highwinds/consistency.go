@@ -0,0 +1,99 @@
+package highwinds
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/openwurl/wurlwind/striketracker/models"
+	"github.com/openwurl/wurlwind/striketracker/services/configuration"
+	"github.com/openwurl/wurlwind/striketracker/services/hosts"
+)
+
+// Errors and string checks
+const (
+	ErrNotConsistent = "%s %s was still incomplete after waiting %s for the API to catch up, raise consistency_timeout if this persists"
+)
+
+// Default read back settings after a write
+const (
+	DefaultConsistencyPollInterval = 2 * time.Second
+	DefaultConsistencyTimeout      = 60 * time.Second
+)
+
+// waitUntilVisible polls complete until the API returns the written object in
+// full. A 404 counts as not visible yet, any other error ends the wait
+func (m *Meta) waitUntilVisible(ctx context.Context, resourceType string, id string, complete func(ctx context.Context) (bool, error)) error {
+	if m.consistencyTimeout <= 0 {
+		return nil
+	}
+
+	pollCtx, cancel := context.WithTimeout(ctx, m.consistencyTimeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		done, err := complete(pollCtx)
+		if done {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if pollCtx.Err() != nil {
+			return fmt.Errorf(ErrNotConsistent, resourceType, id, m.consistencyTimeout)
+		}
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+
+		log.Printf("[DEBUG] %s %s is not complete yet (attempt %d), checking again in %s", resourceType, id, attempt, m.consistencyInterval)
+		if err := sleepContext(pollCtx, m.consistencyInterval); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf(ErrNotConsistent, resourceType, id, m.consistencyTimeout)
+		}
+	}
+}
+
+// waitForHost waits until a written host reads back complete, bypassing the
+// host cache
+func (m *Meta) waitForHost(ctx context.Context, accountHash string, hostHash string) error {
+	defer m.invalidateHost(accountHash, hostHash)
+
+	return m.waitUntilVisible(ctx, "striketracker_host", hostHash, func(ctx context.Context) (bool, error) {
+		ctx = withStatusRecorder(ctx)
+		host, err := hosts.New(m.Client).Get(ctx, accountHash, hostHash)
+		if err := lookupError(ctx, err, host != nil, "host", hostHash); err != nil {
+			return false, err
+		}
+		return hostComplete(host), nil
+	})
+}
+
+// waitForConfiguration waits until a written configuration scope reads back
+// with its platform and path
+func (m *Meta) waitForConfiguration(ctx context.Context, accountHash string, hostHash string, scopeID int) error {
+	id := fmt.Sprintf("%s/%s/%d", accountHash, hostHash, scopeID)
+
+	return m.waitUntilVisible(ctx, "striketracker_configuration", id, func(ctx context.Context) (bool, error) {
+		ctx = withStatusRecorder(ctx)
+		config, err := configuration.New(m.Client).Get(ctx, accountHash, hostHash, scopeID)
+		if err := lookupError(ctx, err, config != nil, "configuration", id); err != nil {
+			return false, err
+		}
+		return configurationComplete(config), nil
+	})
+}
+
+// hostComplete reports whether a host carries the fields Read requires, a
+// host without a CDS scope reads back with an empty root_scope_id
+func hostComplete(host *models.Host) bool {
+	return host.HashCode != ""
+}
+
+// configurationComplete reports whether a scope carries the fields Read requires
+func configurationComplete(config *models.Configuration) bool {
+	return config.Platform != "" && config.ID != 0 && config.Path != ""
+}
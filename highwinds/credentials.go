@@ -0,0 +1,132 @@
+package highwinds
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Errors and string checks
+const (
+	ErrCredentialsFileRead  = "unable to read credentials file %s: %v"
+	ErrCredentialsFileParse = "%s:%d: expected [profile] or key = value, got %q"
+	ErrCredentialsProfile   = "profile %q not found in credentials file %s"
+)
+
+const (
+	// DefaultCredentialsFile is read when credentials_file is not set
+	DefaultCredentialsFile = "~/.striketracker/credentials"
+
+	// DefaultProfile is the credentials file section used when profile is not set
+	DefaultProfile = "default"
+
+	// DefaultApplicationID is sent when no application_id is configured anywhere
+	DefaultApplicationID = "wurlwind-terraform"
+)
+
+// credentialsProfiles maps profile name to its key/value pairs
+type credentialsProfiles map[string]map[string]string
+
+// applyCredentialsFile fills settings that were not set in HCL or the
+// environment from the named profile of the credentials file. A missing
+// file is only an error when the file or a non-default profile was asked for
+func (c *Config) applyCredentialsFile(path string, profile string) error {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultCredentialsFile
+	}
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	expanded, err := expandHome(path)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := readCredentialsFile(expanded)
+	if os.IsNotExist(err) && !explicit && profile == DefaultProfile {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf(ErrCredentialsFileRead, path, err)
+	}
+
+	values, ok := profiles[profile]
+	if !ok {
+		if !explicit && profile == DefaultProfile {
+			return nil
+		}
+		return fmt.Errorf(ErrCredentialsProfile, profile, path)
+	}
+
+	// The credentials are taken as a unit, a token or username set in HCL or
+	// the environment must not be overridden by the other kind from the file
+	if c.AuthorizationHeaderKey == "" && c.Username == "" {
+		c.AuthorizationHeaderKey = values["authorization_header_key"]
+		c.Username = values["username"]
+		setIfEmpty(&c.Password, values["password"])
+	}
+	setIfEmpty(&c.ApplicationID, values["application_id"])
+	setIfEmpty(&c.AccountHash, values["account_hash"])
+
+	return nil
+}
+
+// readCredentialsFile parses an ini style file of [profile] sections
+func readCredentialsFile(path string) (credentialsProfiles, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles := credentialsProfiles{}
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := profiles[current]; !ok {
+				profiles[current] = map[string]string{}
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || current == "" {
+			return nil, fmt.Errorf(ErrCredentialsFileParse, path, lineNumber, line)
+		}
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"`)
+		profiles[current][key] = value
+	}
+
+	return profiles, scanner.Err()
+}
+
+// expandHome resolves a leading ~ to the user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+func setIfEmpty(target *string, value string) {
+	if *target == "" {
+		*target = value
+	}
+}
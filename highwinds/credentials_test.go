@@ -0,0 +1,151 @@
+package highwinds
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCredentials = `# shared credentials
+[default]
+authorization_header_key = file-token
+application_id = file-app
+account_hash = file-account
+
+[customer-a]
+username = "ci@example.com"
+password = file-password
+`
+
+// writeCredentials writes a credentials file into a new temporary directory
+func writeCredentials(t *testing.T, contents string) (string, func()) {
+	dir, err := ioutil.TempDir("", "striketracker-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "credentials")
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestApplyCredentialsFile(t *testing.T) {
+	path, cleanup := writeCredentials(t, testCredentials)
+	defer cleanup()
+
+	cases := []struct {
+		name    string
+		config  Config
+		profile string
+		want    Config
+	}{
+		{
+			name: "file fills everything",
+			want: Config{AuthorizationHeaderKey: "file-token", ApplicationID: "file-app", AccountHash: "file-account"},
+		},
+		{
+			name:   "HCL or env token and settings win",
+			config: Config{AuthorizationHeaderKey: "hcl-token", ApplicationID: "hcl-app"},
+			want:   Config{AuthorizationHeaderKey: "hcl-token", ApplicationID: "hcl-app", AccountHash: "file-account"},
+		},
+		{
+			name:   "HCL or env username keeps the file token out",
+			config: Config{Username: "hcl-user", Password: "hcl-password"},
+			want:   Config{Username: "hcl-user", Password: "hcl-password", ApplicationID: "file-app", AccountHash: "file-account"},
+		},
+		{
+			name:    "HCL or env token keeps the file login out",
+			config:  Config{AuthorizationHeaderKey: "hcl-token"},
+			profile: "customer-a",
+			want:    Config{AuthorizationHeaderKey: "hcl-token"},
+		},
+		{
+			name:    "named profile",
+			profile: "customer-a",
+			want:    Config{Username: "ci@example.com", Password: "file-password"},
+		},
+	}
+
+	for _, tc := range cases {
+		config := tc.config
+		if err := config.applyCredentialsFile(path, tc.profile); err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(config, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.name, config, tc.want)
+		}
+	}
+}
+
+func TestApplyCredentialsFileMissing(t *testing.T) {
+	home, err := ioutil.TempDir("", "striketracker-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	previousHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", previousHome)
+
+	path, cleanup := writeCredentials(t, testCredentials)
+	defer cleanup()
+
+	cases := []struct {
+		name    string
+		path    string
+		profile string
+		wantErr string
+	}{
+		{name: "missing default file", path: "", profile: ""},
+		{name: "missing default file, default profile named", path: "", profile: DefaultProfile},
+		{name: "missing default file, other profile", path: "", profile: "customer-b", wantErr: "unable to read credentials file"},
+		{name: "missing explicit file", path: filepath.Join(home, "nope"), profile: "", wantErr: "unable to read credentials file"},
+		{name: "missing explicit profile", path: path, profile: "customer-b", wantErr: `profile "customer-b" not found`},
+	}
+
+	for _, tc := range cases {
+		config := Config{}
+		err := config.applyCredentialsFile(tc.path, tc.profile)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: got error %v, want one containing %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestReadCredentialsFileParseErrors(t *testing.T) {
+	cases := []struct {
+		contents string
+		wantLine string
+	}{
+		{contents: "authorization_header_key = before-any-profile\n", wantLine: ":1:"},
+		{contents: "[default]\n\n# comment\nnot a pair\n", wantLine: ":4:"},
+		{contents: "; comment\n[default]\nusername = ci\n[other]\npassword\n", wantLine: ":5:"},
+	}
+
+	for _, tc := range cases {
+		path, cleanup := writeCredentials(t, tc.contents)
+		_, err := readCredentialsFile(path)
+		cleanup()
+
+		if err == nil {
+			t.Errorf("readCredentialsFile(%q): expected an error", tc.contents)
+			continue
+		}
+		if !strings.Contains(err.Error(), path+tc.wantLine) {
+			t.Errorf("readCredentialsFile(%q) = %v, want it to point at %s%s", tc.contents, err, path, tc.wantLine)
+		}
+	}
+}
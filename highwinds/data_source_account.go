@@ -0,0 +1,121 @@
+package highwinds
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/openwurl/wurlwind/pkg/debug"
+)
+
+func dataSourceAccount() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceAccountRead,
+		Schema: map[string]*schema.Schema{
+			"account_hash": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"name"},
+				Description:   "The account to read, defaults to the account of the provider credentials when neither this nor name is set",
+			},
+			"name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"account_hash"},
+				Description:   "The exact name of an account the provider credentials can reach",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The account status, such as ACTIVE",
+			},
+			"parent_account_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The hash of the parent account, empty for a root account",
+			},
+			"created_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the account was created",
+			},
+			"sub_accounts": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The direct sub-accounts of the account",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"account_hash": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+/*
+	Read
+*/
+func dataSourceAccountRead(d *schema.ResourceData, m interface{}) error {
+	meta := m.(*Meta)
+
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
+	defer cancel()
+
+	accountHash := d.Get("account_hash").(string)
+	if name, ok := d.GetOk("name"); ok {
+		debug.Log("Read", "Looking up account named %s", name)
+
+		account, err := meta.findAccountByName(ctx, meta.RootAccountHash, name.(string))
+		if err != nil {
+			return err
+		}
+		accountHash = account.AccountHash
+	}
+	// With neither set, read the account the credentials belong to
+	if accountHash == "" {
+		accountHash = meta.RootAccountHash
+	}
+
+	account, err := meta.getAccount(ctx, accountHash)
+	if err != nil {
+		return err
+	}
+	subAccounts, err := meta.listSubAccounts(ctx, accountHash)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(account.AccountHash)
+	d.Set("account_hash", account.AccountHash)
+	d.Set("name", account.AccountName)
+	d.Set("status", account.AccountStatus)
+	d.Set("parent_account_hash", account.ParentAccountHash)
+	d.Set("created_date", account.CreatedDate)
+
+	subAccountList := make([]map[string]interface{}, 0, len(subAccounts))
+	for _, subAccount := range subAccounts {
+		subAccountList = append(subAccountList, map[string]interface{}{
+			"account_hash": subAccount.AccountHash,
+			"name":         subAccount.AccountName,
+			"status":       subAccount.AccountStatus,
+		})
+	}
+	if err := d.Set("sub_accounts", subAccountList); err != nil {
+		return fmt.Errorf("error setting sub_accounts on %s: %v", account.AccountHash, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package highwinds
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/openwurl/wurlwind/pkg/debug"
+)
+
+func dataSourceAccounts() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceAccountsRead,
+		Schema: map[string]*schema.Schema{
+			"root_account_hash": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The account to start from, defaults to the provider account",
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateRegexp,
+				Description:  "Only return sub-accounts whose name matches this regular expression",
+			},
+			"recursive": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Walk the whole sub-account tree instead of only the direct sub-accounts",
+			},
+			"account_hashes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The hashes of the matching sub-accounts",
+			},
+			"accounts": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching sub-accounts, breadth first",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"account_hash": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"parent_account_hash": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"depth": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "1 for direct sub-accounts of the root, 2 for theirs and so on",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+/*
+	Read
+*/
+func dataSourceAccountsRead(d *schema.ResourceData, m interface{}) error {
+	meta := m.(*Meta)
+
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
+	defer cancel()
+
+	rootHash := d.Get("root_account_hash").(string)
+	if rootHash == "" {
+		rootHash = meta.AccountHash
+	}
+	if rootHash == "" {
+		rootHash = meta.RootAccountHash
+	}
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+	recursive := d.Get("recursive").(bool)
+
+	debug.Log("Read", "Listing sub-accounts of %s (recursive: %t)", rootHash, recursive)
+
+	hashes := []string{}
+	accounts := []map[string]interface{}{}
+	err := meta.walkSubAccounts(ctx, rootHash, recursive, func(account *Account, parentHash string, depth int) bool {
+		if nameRegex == nil || nameRegex.MatchString(account.AccountName) {
+			hashes = append(hashes, account.AccountHash)
+			accounts = append(accounts, map[string]interface{}{
+				"account_hash":        account.AccountHash,
+				"name":                account.AccountName,
+				"status":              account.AccountStatus,
+				"parent_account_hash": parentHash,
+				"depth":               depth,
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(rootHash)
+	d.Set("root_account_hash", rootHash)
+	d.Set("account_hashes", hashes)
+	if err := d.Set("accounts", accounts); err != nil {
+		return fmt.Errorf("error setting accounts below %s: %v", rootHash, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package highwinds
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/openwurl/wurlwind/pkg/debug"
+)
+
+func dataSourceHost() *schema.Resource {
+	hostSchema := dataSourceSchema(resourceHost().Schema, "adopt_existing", "deletion_protection")
+
+	hostSchema["account_hash"].Optional = true
+	hostSchema["account_hash"].Description = "The account to search, defaults to the provider account_hash"
+	hostSchema["name"].Optional = true
+	hostSchema["name"].ConflictsWith = []string{"hash_code"}
+	hostSchema["hash_code"].Optional = true
+	hostSchema["hash_code"].ConflictsWith = []string{"name"}
+
+	return &schema.Resource{
+		Read:   dataSourceHostRead,
+		Schema: hostSchema,
+	}
+}
+
+/*
+	Read
+*/
+func dataSourceHostRead(d *schema.ResourceData, m interface{}) error {
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
+	defer cancel()
+
+	hostHash := d.Get("hash_code").(string)
+	if name, ok := d.GetOk("name"); ok {
+		debug.Log("Read", "Looking up host named %s in %s", name, accountHash)
+
+		host, err := m.(*Meta).findHostByName(ctx, accountHash, name.(string))
+		if err != nil {
+			return err
+		}
+		if host == nil {
+			return fmt.Errorf(ErrNoMatches, "hosts", accountHash, fmt.Sprintf("name %q", name))
+		}
+		hostHash = host.HashCode
+	}
+	if hostHash == "" {
+		return fmt.Errorf(ErrNoLookupKey, "name or hash_code")
+	}
+
+	// The listing does not carry scopes, read the host itself
+	hostResource, err := m.(*Meta).getHost(ctx, accountHash, hostHash)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(hostResource.HashCode)
+	return setHostState(d, hostResource)
+}
@@ -0,0 +1,81 @@
+package highwinds
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/openwurl/wurlwind/pkg/debug"
+	"github.com/openwurl/wurlwind/striketracker/models"
+)
+
+// Errors and string checks
+const (
+	ErrNoLookupKey = "one of %s must be set"
+)
+
+func dataSourceOrigin() *schema.Resource {
+	originSchema := dataSourceSchema(resourceOrigin().Schema, "adopt_existing", "deletion_protection")
+
+	originSchema["account_hash"].Optional = true
+	originSchema["account_hash"].Description = "The account to search, defaults to the provider account_hash"
+	originSchema["name"].Optional = true
+	originSchema["name"].ConflictsWith = []string{"hostname"}
+	originSchema["hostname"].Optional = true
+	originSchema["hostname"].ConflictsWith = []string{"name"}
+
+	return &schema.Resource{
+		Read:   dataSourceOriginRead,
+		Schema: originSchema,
+	}
+}
+
+/*
+	Read
+*/
+func dataSourceOriginRead(d *schema.ResourceData, m interface{}) error {
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
+	defer cancel()
+
+	var field, value string
+	if v, ok := d.GetOk("name"); ok {
+		field, value = "name", v.(string)
+	} else if v, ok := d.GetOk("hostname"); ok {
+		field, value = "hostname", v.(string)
+	} else {
+		return fmt.Errorf(ErrNoLookupKey, "name or hostname")
+	}
+
+	debug.Log("Read", "Looking up origin with %s %s in %s", field, value, accountHash)
+
+	originList, err := m.(*Meta).listOrigins(ctx, accountHash)
+	if err != nil {
+		return err
+	}
+
+	var matches []*models.Origin
+	for _, o := range originList {
+		if (field == "name" && o.Name == value) || (field == "hostname" && o.Hostname == value) {
+			matches = append(matches, o)
+		}
+	}
+
+	match := fmt.Sprintf("%s %q", field, value)
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf(ErrNoMatches, "origins", accountHash, match)
+	case 1:
+	default:
+		return fmt.Errorf(ErrMultipleMatches, len(matches), "origins", accountHash, match)
+	}
+
+	d.SetId(strconv.Itoa(matches[0].ID))
+	setOriginState(d, matches[0])
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package highwinds
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/openwurl/wurlwind/pkg/debug"
+)
+
+func dataSourceOrigins() *schema.Resource {
+	originSchema := dataSourceSchema(resourceOrigin().Schema, "adopt_existing", "deletion_protection", "account_hash")
+	originSchema["id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The ID of the origin",
+	}
+
+	return &schema.Resource{
+		Read: dataSourceOriginsRead,
+		Schema: map[string]*schema.Schema{
+			"account_hash": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The account to list, defaults to the provider account_hash",
+			},
+			"hostname_suffix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return origins whose hostname ends with this suffix",
+			},
+			"port": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Only return origins on this port",
+			},
+			"verify_certificate": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return origins that do (true) or do not (false) verify the origin certificate",
+			},
+			"authentication_type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return origins with this authentication type, NONE or BASIC",
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The IDs of the matching origins",
+			},
+			"origins": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching origins with every attribute the striketracker_origin resource reads",
+				Elem:        &schema.Resource{Schema: originSchema},
+			},
+		},
+	}
+}
+
+/*
+	Read
+*/
+func dataSourceOriginsRead(d *schema.ResourceData, m interface{}) error {
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
+	defer cancel()
+
+	debug.Log("Read", "Listing origins in %s", accountHash)
+
+	originList, err := m.(*Meta).listOrigins(ctx, accountHash)
+	if err != nil {
+		return err
+	}
+
+	suffix := strings.ToLower(d.Get("hostname_suffix").(string))
+	port, filterPort := d.GetOk("port")
+	verify, filterVerify := d.GetOkExists("verify_certificate")
+	authenticationType := d.Get("authentication_type").(string)
+
+	ids := []string{}
+	origins := []map[string]interface{}{}
+	for _, o := range originList {
+		if suffix != "" && !strings.HasSuffix(strings.ToLower(o.Hostname), suffix) {
+			continue
+		}
+		if filterPort && o.Port != port.(int) {
+			continue
+		}
+		if filterVerify && o.VerifyCertificate != verify.(bool) {
+			continue
+		}
+		if authenticationType != "" && !strings.EqualFold(o.AuthenticationType, authenticationType) {
+			continue
+		}
+
+		id := strconv.Itoa(o.ID)
+		origin := flattenOrigin(o)
+		origin["id"] = id
+
+		ids = append(ids, id)
+		origins = append(origins, origin)
+	}
+
+	d.SetId(accountHash)
+	d.Set("ids", ids)
+	if err := d.Set("origins", origins); err != nil {
+		return fmt.Errorf("error setting origins for %s: %v", accountHash, err)
+	}
+
+	return nil
+}
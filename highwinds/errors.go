@@ -0,0 +1,209 @@
+package highwinds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Errors and string checks
+const (
+	ErrResourceNotFound = "%s %s was not returned by the API"
+)
+
+// APIErrorKind classifies a failed StrikeTracker API call
+type APIErrorKind int
+
+const (
+	// APIErrorUnknown is any failure that is not one of the kinds below
+	APIErrorUnknown APIErrorKind = iota
+
+	// APIErrorNotFound is a 404 or 410, the resource no longer exists
+	APIErrorNotFound
+
+	// APIErrorAuth is a 401 or 403, the credentials were rejected
+	APIErrorAuth
+
+	// APIErrorValidation is a 400, 409 or 422, the request was refused as invalid
+	APIErrorValidation
+
+	// APIErrorThrottled is a 429 that outlasted the retries
+	APIErrorThrottled
+)
+
+func (k APIErrorKind) String() string {
+	switch k {
+	case APIErrorNotFound:
+		return "not found"
+	case APIErrorAuth:
+		return "authentication"
+	case APIErrorValidation:
+		return "validation"
+	case APIErrorThrottled:
+		return "throttled"
+	}
+	return "unknown"
+}
+
+// APIError wraps an error from the striketracker client with the HTTP
+// status that produced it. The message is the client's, unchanged
+type APIError struct {
+	Kind       APIErrorKind
+	StatusCode int
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the client error
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound reports whether err is a genuine 404 from the API
+func IsNotFound(err error) bool {
+	return isAPIErrorKind(err, APIErrorNotFound)
+}
+
+// IsAuthError reports whether the API rejected the credentials
+func IsAuthError(err error) bool {
+	return isAPIErrorKind(err, APIErrorAuth)
+}
+
+// IsValidationError reports whether the API refused the request as invalid
+func IsValidationError(err error) bool {
+	return isAPIErrorKind(err, APIErrorValidation)
+}
+
+// IsThrottled reports whether the API kept rate limiting the request
+func IsThrottled(err error) bool {
+	return isAPIErrorKind(err, APIErrorThrottled)
+}
+
+func isAPIErrorKind(err error, kind APIErrorKind) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Kind == kind
+}
+
+// kindForStatus maps an HTTP status onto an APIErrorKind
+func kindForStatus(status int) APIErrorKind {
+	switch status {
+	case http.StatusNotFound, http.StatusGone:
+		return APIErrorNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return APIErrorAuth
+	case http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity:
+		return APIErrorValidation
+	case http.StatusTooManyRequests:
+		return APIErrorThrottled
+	}
+	return APIErrorUnknown
+}
+
+// wrapAPIError classifies err by the status recorded on a context from
+// withStatusRecorder. Errors that are already classified pass through
+func wrapAPIError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return err
+	}
+
+	status := recordedStatus(ctx)
+	var respErr *apiResponseError
+	if errors.As(err, &respErr) {
+		status = respErr.StatusCode
+	}
+	if status >= 200 && status <= 299 {
+		// The failure happened after a successful response, decoding for example
+		status = 0
+	}
+
+	return &APIError{Kind: kindForStatus(status), StatusCode: status, Err: err}
+}
+
+// lookupError classifies the result of a GET. The client can answer a
+// missing resource with a nil model and no error, which only counts as not
+// found when the API really returned a 404
+func lookupError(ctx context.Context, err error, found bool, resourceType string, id string) error {
+	if err != nil {
+		return wrapAPIError(ctx, err)
+	}
+	if found {
+		return nil
+	}
+
+	status := recordedStatus(ctx)
+	return &APIError{
+		Kind:       kindForStatus(status),
+		StatusCode: status,
+		Err:        fmt.Errorf(ErrResourceNotFound, resourceType, id),
+	}
+}
+
+// removeIfNotFound clears the ID of a resource that was deleted outside of
+// Terraform so the next plan recreates it, any other error is returned as is
+func removeIfNotFound(d *schema.ResourceData, resourceType string, err error) error {
+	if !IsNotFound(err) {
+		return err
+	}
+	log.Printf("[WARN] %s %s no longer exists, removing it from state", resourceType, d.Id())
+	d.SetId("")
+	return nil
+}
+
+// statusRecorder captures the final HTTP status of the calls made with a context
+type statusRecorder struct {
+	mu     sync.Mutex
+	status int
+}
+
+type statusRecorderKey struct{}
+
+// withStatusRecorder returns a context that records the HTTP status of requests made with it
+func withStatusRecorder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, statusRecorderKey{}, &statusRecorder{})
+}
+
+// recordedStatus returns the last status seen on a context from withStatusRecorder
+func recordedStatus(ctx context.Context) int {
+	if recorder, ok := ctx.Value(statusRecorderKey{}).(*statusRecorder); ok {
+		return recorder.get()
+	}
+	return 0
+}
+
+func (r *statusRecorder) set(status int) {
+	r.mu.Lock()
+	r.status = status
+	r.mu.Unlock()
+}
+
+func (r *statusRecorder) get() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// statusTransport stores the response status on the request context's recorder
+type statusTransport struct {
+	next http.RoundTripper
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if recorder, ok := req.Context().Value(statusRecorderKey{}).(*statusRecorder); ok && resp != nil {
+		recorder.set(resp.StatusCode)
+	}
+	return resp, err
+}
@@ -0,0 +1,72 @@
+package highwinds
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Errors and string checks
+const (
+	ErrReadOnly          = "refusing to %s %s: the provider is configured with read_only = true"
+	ErrReadOnlyTransport = "refusing to send %s %s: the provider is configured with read_only = true"
+	ErrDeletionProtected = "refusing to delete %s %s: deletion_protection is enabled on the resource"
+	ErrPreventDestroy    = "refusing to delete %s %s: the provider lists %s in prevent_destroy_types"
+	ErrUnknownType       = "prevent_destroy_types contains %s which is not a striketracker resource type"
+)
+
+// checkWritable refuses create, update and delete when the provider is read only
+func checkWritable(m interface{}, action string, resourceType string) error {
+	if m.(*Meta).ReadOnly {
+		return fmt.Errorf(ErrReadOnly, action, resourceType)
+	}
+	return nil
+}
+
+// checkDeletionProtection refuses to delete a resource that is protected
+// itself or whose type the provider protects
+func checkDeletionProtection(d *schema.ResourceData, m interface{}, resourceType string) error {
+	if d.Get("deletion_protection").(bool) {
+		return fmt.Errorf(ErrDeletionProtected, resourceType, d.Id())
+	}
+	if m.(*Meta).PreventDestroyTypes[resourceType] {
+		return fmt.Errorf(ErrPreventDestroy, resourceType, d.Id(), resourceType)
+	}
+	return nil
+}
+
+// localOnlyKeys only change how the provider treats a resource, never the
+// remote object
+var localOnlyKeys = map[string]bool{
+	"deletion_protection": true,
+	"adopt_existing":      true,
+}
+
+// onlyLocalChanges reports whether an update of an existing resource touches
+// nothing but local only arguments, so it needs no API call
+func onlyLocalChanges(d *schema.ResourceData, resourceSchema map[string]*schema.Schema) bool {
+	if d.IsNewResource() {
+		return false
+	}
+	for key := range resourceSchema {
+		if !localOnlyKeys[key] && d.HasChange(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// readOnlyTransport is a last line of defence that refuses any mutating
+// request in read only mode, even one sent outside the resource CRUD paths
+type readOnlyTransport struct {
+	next http.RoundTripper
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.next.RoundTrip(req)
+	}
+	return nil, fmt.Errorf(ErrReadOnlyTransport, req.Method, req.URL.Path)
+}
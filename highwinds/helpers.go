@@ -3,28 +3,137 @@ package highwinds
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
 )
 
 // Errors and string checks
 const (
 	ErrBadImportParse = "unexpected format of import ID (%s), expected account_hash/ID"
+	ErrNoAccountHash  = "account_hash is not set on the resource and the provider has no default account_hash"
 )
 
-func getContext() (context.Context, context.CancelFunc) {
-	ctx := context.Background()
-	return context.WithTimeout(ctx, 8*time.Second)
+// Default timeouts for resource operations and individual API requests
+const (
+	DefaultResourceTimeout = 10 * time.Minute
+	DefaultRequestTimeout  = 60 * time.Second
+)
+
+// getContext returns a context bounded by the resource timeout for the given
+// operation (schema.TimeoutCreate, schema.TimeoutRead, ...) that is also
+// cancelled when Terraform is interrupted
+func getContext(d *schema.ResourceData, m interface{}, timeoutKey string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(m.(*Meta).stopContext(), d.Timeout(timeoutKey))
+}
+
+// resourceTimeouts is the timeouts block shared by all resources
+func resourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create:  schema.DefaultTimeout(DefaultResourceTimeout),
+		Read:    schema.DefaultTimeout(DefaultResourceTimeout),
+		Update:  schema.DefaultTimeout(DefaultResourceTimeout),
+		Delete:  schema.DefaultTimeout(DefaultResourceTimeout),
+		Default: schema.DefaultTimeout(DefaultResourceTimeout),
+	}
+}
+
+// validateDuration ensures a string field parses as a time.Duration
+func validateDuration(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	duration, err := time.ParseDuration(v)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a duration such as 30s or 5m, got %s", key, v))
+		return warns, errs
+	}
+	if duration < 0 {
+		errs = append(errs, fmt.Errorf("%q must not be negative, got %s", key, v))
+	}
+	return warns, errs
+}
+
+// validatePositiveDuration ensures a string field parses as a time.Duration above zero
+func validatePositiveDuration(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	duration, err := time.ParseDuration(v)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a duration such as 30s or 5m, got %s", key, v))
+		return warns, errs
+	}
+	if duration <= 0 {
+		errs = append(errs, fmt.Errorf("%q must be greater than zero, got %s", key, v))
+	}
+	return warns, errs
+}
+
+// validateRegexp ensures a string field compiles as a regular expression
+func validateRegexp(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if _, err := regexp.Compile(v); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a valid regular expression: %v", key, err))
+	}
+	return warns, errs
+}
+
+// dataSourceSchema copies a resource schema with every attribute computed so
+// a data source can expose the same fields, leaving out the omitted keys
+func dataSourceSchema(resourceSchema map[string]*schema.Schema, omit ...string) map[string]*schema.Schema {
+	omitted := map[string]bool{}
+	for _, key := range omit {
+		omitted[key] = true
+	}
+
+	computed := map[string]*schema.Schema{}
+	for key, field := range resourceSchema {
+		if omitted[key] {
+			continue
+		}
+
+		elem := field.Elem
+		if resource, ok := elem.(*schema.Resource); ok {
+			elem = &schema.Resource{Schema: dataSourceSchema(resource.Schema)}
+		}
+		computed[key] = &schema.Schema{
+			Type:        field.Type,
+			Description: field.Description,
+			Elem:        elem,
+			Sensitive:   field.Sensitive,
+			Computed:    true,
+		}
+	}
+	return computed
+}
+
+// getAccountHash returns the account_hash of the resource, falling back to
+// the provider default when the resource does not set one
+func getAccountHash(d *schema.ResourceData, m interface{}) (string, error) {
+	if v, ok := d.GetOk("account_hash"); ok {
+		return v.(string), nil
+	}
+
+	accountHash := m.(*Meta).AccountHash
+	if accountHash == "" {
+		return "", fmt.Errorf(ErrNoAccountHash)
+	}
+	d.Set("account_hash", accountHash)
+
+	return accountHash, nil
 }
 
 // ResourceImportParseHashID Highwinds requires an account_hash in addition to
 // the resource ID, so on imports we must input account_hash/ID unless
 // someone knows a way to get the Importer func to get it from the resource
-// definition
+// definition. A bare ID is accepted when the provider has a default account_hash
 // https://www.terraform.io/docs/extend/resources/import.html
-func ResourceImportParseHashID(input string) (string, string, error) {
+func ResourceImportParseHashID(input string, defaultAccountHash string) (string, string, error) {
 	parts := strings.SplitN(input, "/", 2)
 
+	if len(parts) == 1 && parts[0] != "" && defaultAccountHash != "" {
+		return defaultAccountHash, parts[0], nil
+	}
+
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
 		return "", "", fmt.Errorf(ErrBadImportParse, input)
 	}
@@ -33,13 +142,18 @@ func ResourceImportParseHashID(input string) (string, string, error) {
 }
 
 // ResourceConfigurationParseHashID configuration scopes have an additional field required
-// You need account hash, host hash, and SCOPE ID to import
-func ResourceConfigurationParseHashID(input string) (string, string, string, error) {
+// You need account hash, host hash, and SCOPE ID to import, or only
+// host hash and SCOPE ID when the provider has a default account_hash
+func ResourceConfigurationParseHashID(input string, defaultAccountHash string) (string, string, string, error) {
 	parts := strings.SplitN(input, "/", 3)
 
+	if len(parts) == 2 && parts[0] != "" && parts[1] != "" && defaultAccountHash != "" {
+		return defaultAccountHash, parts[0], parts[1], nil
+	}
+
 	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
 		return "", "", "", fmt.Errorf(ErrBadImportParse, input)
 	}
 
-	return parts[0], parts[1], parts[3], nil
+	return parts[0], parts[1], parts[2], nil
 }
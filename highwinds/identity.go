@@ -0,0 +1,96 @@
+package highwinds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors and string checks
+const (
+	ErrInvalidCredentials = "the StrikeTracker credentials were rejected, check authorization_header_key or username/password: %v"
+	ErrNoAccountAccess    = "user %s does not have access to account %s: %v"
+	ErrNotSubAccount      = "sub_account_hash %s is not a sub-account of %s that user %s can manage"
+)
+
+// CurrentUser is the user the provider credentials belong to
+type CurrentUser struct {
+	ID          int    `json:"id"`
+	Username    string `json:"username"`
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
+	Email       string `json:"email"`
+	AccountHash string `json:"accountHash"`
+	UserType    string `json:"userType"`
+}
+
+// discoverIdentity resolves the current user and checks it can reach the
+// configured account
+func (m *Meta) discoverIdentity(ctx context.Context) error {
+	user := &CurrentUser{}
+	if err := m.apiGet(ctx, "/api/v1/users/me", nil, user); err != nil {
+		if isStatus(err, http.StatusUnauthorized, http.StatusForbidden) {
+			return fmt.Errorf(ErrInvalidCredentials, err)
+		}
+		return err
+	}
+	m.User = user
+	m.RootAccountHash = user.AccountHash
+
+	if m.AccountHash != "" && m.AccountHash != m.RootAccountHash {
+		if err := m.checkAccountAccess(ctx, m.AccountHash); err != nil {
+			return err
+		}
+	}
+
+	if m.subAccountHash != "" {
+		return m.assumeSubAccount(ctx, m.subAccountHash)
+	}
+
+	return nil
+}
+
+// assumeSubAccount checks that the child account sits below the configured
+// (or the user's own) account and makes it the default for every resource
+func (m *Meta) assumeSubAccount(ctx context.Context, subAccountHash string) error {
+	parentHash := m.AccountHash
+	if parentHash == "" {
+		parentHash = m.RootAccountHash
+	}
+
+	account, err := m.findSubAccount(ctx, parentHash, subAccountHash)
+	if isStatus(err, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound) || (err == nil && account == nil) {
+		return fmt.Errorf(ErrNotSubAccount, subAccountHash, parentHash, m.User.Username)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.ParentAccountHash = parentHash
+	m.AccountHash = subAccountHash
+	return nil
+}
+
+// checkAccountAccess ensures the current user can read the given account
+func (m *Meta) checkAccountAccess(ctx context.Context, accountHash string) error {
+	err := m.apiGet(ctx, fmt.Sprintf("/api/v1/accounts/%s", accountHash), nil, nil)
+	if isStatus(err, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound) {
+		return fmt.Errorf(ErrNoAccountAccess, m.User.Username, accountHash, err)
+	}
+	return err
+}
+
+// isStatus reports whether err is a direct API call that failed with one of the given statuses
+func isStatus(err error, statuses ...int) bool {
+	var apiErr *apiResponseError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, status := range statuses {
+		if apiErr.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package highwinds
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// requestIDHeader carries the correlation ID of every API request
+	requestIDHeader = "X-Request-ID"
+
+	// redacted replaces secret values in logs
+	redacted = "<redacted>"
+)
+
+// redactedFields are body fields that are never logged, compared lowercased
+var redactedFields = map[string]bool{
+	"key":           true,
+	"cabundle":      true,
+	"ca_bundle":     true,
+	"password":      true,
+	"access_token":  true,
+	"refresh_token": true,
+}
+
+// redactedHeaders are headers that are never logged
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// wireLoggingEnabled reports whether request/response bodies should be logged
+func wireLoggingEnabled() bool {
+	return strings.EqualFold(os.Getenv("TF_LOG"), "TRACE")
+}
+
+// requestIDTransport tags every request with a correlation ID so attempts
+// of the same request can be told apart from concurrent operations
+type requestIDTransport struct {
+	next http.RoundTripper
+}
+
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(requestIDHeader) != "" {
+		return t.next.RoundTrip(req)
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set(requestIDHeader, newRequestID())
+	return t.next.RoundTrip(r)
+}
+
+// loggingTransport logs every request and response at TRACE with secrets redacted
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !wireLoggingEnabled() {
+		return t.next.RoundTrip(req)
+	}
+
+	requestID := req.Header.Get(requestIDHeader)
+	if requestID == "" {
+		requestID = newRequestID()
+		req = req.Clone(req.Context())
+		req.Header.Set(requestIDHeader, requestID)
+	}
+
+	reqBody, err := peekRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[TRACE] StrikeTracker request %s: %s %s\n%s%s", requestID, req.Method, req.URL.String(),
+		formatHeaders(req.Header), redactBody(req.Header.Get("Content-Type"), reqBody))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start)
+	if err != nil {
+		log.Printf("[TRACE] StrikeTracker response %s: error after %s: %v", requestID, latency, err)
+		return resp, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[TRACE] StrikeTracker response %s: %s in %s\n%s%s", requestID, resp.Status, latency,
+		formatHeaders(resp.Header), redactBody(resp.Header.Get("Content-Type"), respBody))
+
+	return resp, nil
+}
+
+// peekRequestBody reads the request body without consuming it
+func peekRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return ioutil.ReadAll(body)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// formatHeaders renders headers one per line with secrets redacted
+func formatHeaders(headers http.Header) string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		value := strings.Join(headers[k], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(k)] {
+			value = redacted
+		}
+		fmt.Fprintf(&b, "%s: %s\n", k, value)
+	}
+	return b.String()
+}
+
+// redactBody returns a loggable copy of a JSON or form body
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return redacted
+		}
+		for k := range values {
+			if redactedFields[strings.ToLower(k)] {
+				values.Set(k, redacted)
+			}
+		}
+		return values.Encode()
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return string(body)
+	}
+	out, err := json.Marshal(redactValue(decoded))
+	if err != nil {
+		return redacted
+	}
+	return string(out)
+}
+
+// redactValue walks decoded JSON replacing secret fields
+func redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, inner := range value {
+			if redactedFields[strings.ToLower(k)] {
+				value[k] = redacted
+				continue
+			}
+			value[k] = redactValue(inner)
+		}
+	case []interface{}:
+		for i, inner := range value {
+			value[i] = redactValue(inner)
+		}
+	}
+	return v
+}
+
+// newRequestID returns a random correlation ID
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,88 @@
+package highwinds
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openwurl/wurlwind/striketracker/models"
+	"github.com/openwurl/wurlwind/striketracker/services/hosts"
+	"github.com/openwurl/wurlwind/striketracker/services/origin"
+)
+
+// Errors and string checks
+const (
+	ErrMultipleMatches = "found %d %s in account %s matching %s, expected exactly one"
+	ErrNoMatches       = "found no %s in account %s matching %s"
+)
+
+// listHosts returns every host in the account
+func (m *Meta) listHosts(ctx context.Context, accountHash string) ([]*models.Host, error) {
+	ctx = withStatusRecorder(ctx)
+	hostList, err := hosts.New(m.Client).List(ctx, accountHash)
+	if err != nil {
+		return nil, wrapAPIError(ctx, err)
+	}
+	if hostList == nil {
+		return nil, nil
+	}
+	return hostList.List, nil
+}
+
+// listOrigins returns every origin in the account
+func (m *Meta) listOrigins(ctx context.Context, accountHash string) ([]*models.Origin, error) {
+	ctx = withStatusRecorder(ctx)
+	originList, err := origin.New(m.Client).List(ctx, accountHash)
+	if err != nil {
+		return nil, wrapAPIError(ctx, err)
+	}
+	if originList == nil {
+		return nil, nil
+	}
+	return originList.List, nil
+}
+
+// findHostByName returns the single host with the given name, or nil if there is none
+func (m *Meta) findHostByName(ctx context.Context, accountHash string, name string) (*models.Host, error) {
+	hostList, err := m.listHosts(ctx, accountHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*models.Host
+	for _, host := range hostList {
+		if host.Name == name {
+			matches = append(matches, host)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf(ErrMultipleMatches, len(matches), "hosts", accountHash, fmt.Sprintf("name %q", name))
+}
+
+// findOriginByName returns the single origin with the given name, or nil if there is none
+func (m *Meta) findOriginByName(ctx context.Context, accountHash string, name string) (*models.Origin, error) {
+	originList, err := m.listOrigins(ctx, accountHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*models.Origin
+	for _, o := range originList {
+		if o.Name == name {
+			matches = append(matches, o)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf(ErrMultipleMatches, len(matches), "origins", accountHash, fmt.Sprintf("name %q", name))
+}
@@ -0,0 +1,72 @@
+package highwinds
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/openwurl/wurlwind/striketracker"
+)
+
+// Meta is the provider meta handed to every resource
+type Meta struct {
+	// Client is the configured striketracker client
+	Client *striketracker.Client
+
+	// AccountHash is the provider-wide default account_hash
+	AccountHash string
+
+	// ReadOnly refuses every create, update and delete
+	ReadOnly bool
+
+	// PreventDestroyTypes are resource types that may never be deleted
+	PreventDestroyTypes map[string]bool
+
+	// ChangeWindow limits when hosts and configuration scopes may change
+	ChangeWindow *changeWindow
+
+	// User is the user the provider credentials belong to
+	User *CurrentUser
+
+	// RootAccountHash is the account the user belongs to
+	RootAccountHash string
+
+	// ParentAccountHash is the account the credentials act from when
+	// sub_account_hash is set, AccountHash is then the sub-account
+	ParentAccountHash string
+
+	// endpoint, applicationID and httpClient serve API calls the
+	// striketracker client does not cover
+	endpoint      string
+	applicationID string
+	httpClient    *http.Client
+
+	// hosts caches host lookups for the life of the provider instance
+	hosts *hostCache
+
+	// origins and certificates hold per-account listings used during refresh
+	origins      *listSnapshot
+	certificates *listSnapshot
+
+	// auditLog journals every mutating call when audit_log_path is set
+	auditLog *auditLog
+
+	// subAccountHash is verified and becomes AccountHash during configure
+	subAccountHash string
+
+	// stopCtx is cancelled when Terraform is interrupted
+	stopCtx context.Context
+
+	// consistencyInterval and consistencyTimeout bound the read back of
+	// hosts and configuration scopes after a write
+	consistencyInterval time.Duration
+	consistencyTimeout  time.Duration
+}
+
+// stopContext returns the context every resource operation derives from
+func (m *Meta) stopContext() context.Context {
+	if m.stopCtx == nil {
+		return context.Background()
+	}
+	return m.stopCtx
+}
@@ -1,23 +1,197 @@
 package highwinds
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/openwurl/wurlwind/striketracker"
 )
 
 // Provider provides the striketracker functionality
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"authorization_header_key": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("AUTHORIZATIONHEADERKEY", nil),
+				Sensitive:   true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"STRIKETRACKER_AUTHORIZATION_HEADER_KEY", "AUTHORIZATIONHEADERKEY"}, nil),
+				Description: "A static application token, used when username/password are not set",
+			},
+			"username": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("STRIKETRACKER_USERNAME", nil),
+				Description: "StrikeTracker username exchanged for a bearer token through the auth endpoint",
+			},
+			"password": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("STRIKETRACKER_PASSWORD", nil),
+				Description: "StrikeTracker password for the username",
 			},
 			"application_id": {
 				Type:        schema.TypeString,
-				Required:    true,
-				DefaultFunc: schema.EnvDefaultFunc("APPLICATIONID", "wurlwind-terraform"),
+				Optional:    true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"STRIKETRACKER_APPLICATION_ID", "APPLICATIONID"}, nil),
+				Description: "Application ID sent with every request, defaults to " + DefaultApplicationID,
+			},
+			"credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("STRIKETRACKER_CREDENTIALS_FILE", nil),
+				Description: "Credentials file with [profile] sections, defaults to " + DefaultCredentialsFile,
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("STRIKETRACKER_PROFILE", DefaultProfile),
+				Description: "Profile of the credentials file to use",
+			},
+			"api_endpoint": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("STRIKETRACKER_API_ENDPOINT", DefaultAPIEndpoint),
+				Description: "Base URL of the StrikeTracker API",
+			},
+			"ca_cert_pem": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM encoded CA certificates trusted in addition to the system pool",
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip TLS certificate verification of the API endpoint",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("STRIKETRACKER_PROXY_URL", nil),
+				Description: "Proxy used for API requests, defaults to the HTTPS_PROXY environment",
+			},
+			"account_hash": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("STRIKETRACKER_ACCOUNT_HASH", nil),
+				Description: "Default account hash for resources that do not set their own",
+			},
+			"sub_account_hash": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("STRIKETRACKER_SUB_ACCOUNT_HASH", nil),
+				Description: "A sub-account of account_hash (or of the user's own account) to manage, it becomes the default account for every resource",
+			},
+			"request_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      DefaultRequestTimeout.String(),
+				ValidateFunc: validatePositiveDuration,
+				Description:  "Timeout for a single API request including retries, such as 30s or 2m",
+			},
+			"max_retries": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     DefaultMaxRetries,
+				Description: "How many times throttled or transient API failures are retried, 0 disables retries",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					if val.(int) < 0 {
+						errs = append(errs, fmt.Errorf("%q must not be negative, got %d", key, val))
+					}
+					return warns, errs
+				},
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      DefaultRetryMaxWait.String(),
+				ValidateFunc: validateDuration,
+				Description:  "Upper bound on the backoff between retries, such as 30s",
+			},
+			"max_requests_per_second": {
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				Default:     0,
+				Description: "Client side limit on API requests per second shared by all resources, 0 disables the limit",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					if val.(float64) < 0 {
+						errs = append(errs, fmt.Errorf("%q must not be negative, got %v", key, val))
+					}
+					return warns, errs
+				},
+			},
+			"burst": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "Requests allowed above max_requests_per_second in a burst, defaults to one second worth",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					if val.(int) < 0 {
+						errs = append(errs, fmt.Errorf("%q must not be negative, got %d", key, val))
+					}
+					return warns, errs
+				},
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Refuse every create, update and delete before any mutating API call is sent",
+			},
+			"audit_log_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("STRIKETRACKER_AUDIT_LOG_PATH", nil),
+				Description: "Append a JSON line per create, update and delete to this file",
+			},
+			"prevent_destroy_types": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Resource types, such as striketracker_host, that may never be deleted in this workspace",
+			},
+			"change_window": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Days and hours in which hosts and configuration scopes may be changed",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"days": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "*",
+							Description: "Cron style day of week field, such as mon-fri or 1,3,5",
+						},
+						"hours": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "*",
+							Description: "Cron style hour field, such as 22-23,0-5",
+						},
+						"timezone": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "UTC",
+							Description: "IANA timezone the days and hours are in",
+						},
+					},
+				},
+			},
+			"consistency_poll_interval": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      DefaultConsistencyPollInterval.String(),
+				ValidateFunc: validatePositiveDuration,
+				Description:  "How often a host or configuration scope is read back after a write until the API returns it complete",
+			},
+			"consistency_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      DefaultConsistencyTimeout.String(),
+				ValidateFunc: validateDuration,
+				Description:  "How long to wait for a written host or configuration scope to read back complete, 0 disables the wait",
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
@@ -27,14 +201,26 @@ func Provider() *schema.Provider {
 			"striketracker_configuration":         resourceConfiguration(),
 			"striketracker_default_configuration": defaultResourceConfiguration(),
 		},
-		ConfigureFunc: providerConfigure,
+		DataSourcesMap: map[string]*schema.Resource{
+			"striketracker_account":  dataSourceAccount(),
+			"striketracker_host":     dataSourceHost(),
+			"striketracker_accounts": dataSourceAccounts(),
+			"striketracker_origin":   dataSourceOrigin(),
+			"striketracker_origins":  dataSourceOrigins(),
+		},
 	}
+	p.ConfigureFunc = providerConfigure(p)
+	return p
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	c, err := striketracker.NewClientWithOptions(
-		striketracker.WithAuthorizationHeaderToken(d.Get("authorization_header_key").(string)),
-		striketracker.WithApplicationID(d.Get("application_id").(string)),
-	)
-	return c, err
+// providerConfigure builds the provider meta, bound to the provider's stop
+// context so that cancelling Terraform aborts in-flight API requests
+func providerConfigure(p *schema.Provider) schema.ConfigureFunc {
+	return func(d *schema.ResourceData) (interface{}, error) {
+		config, err := configFromResourceData(d)
+		if err != nil {
+			return nil, err
+		}
+		return config.Meta(p.StopContext())
+	}
 }
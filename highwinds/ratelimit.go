@@ -0,0 +1,75 @@
+package highwinds
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket shared by every request the provider makes
+type rateLimiter struct {
+	mu sync.Mutex
+
+	rate   float64 // tokens added per second
+	burst  float64 // bucket capacity
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter allowing rps requests per second with the
+// given burst, a burst below 1 defaults to one second worth of requests
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	b := float64(burst)
+	if b < 1 {
+		b = math.Max(1, math.Ceil(rps))
+	}
+
+	return &rateLimiter{
+		rate:   rps,
+		burst:  b,
+		tokens: b,
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent or the context is done
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	// Reserve a token up front, a negative balance queues callers in order
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if err := sleepContext(ctx, wait); err != nil {
+		// Hand the reservation back so cancelled callers do not slow others down
+		l.mu.Lock()
+		l.tokens = math.Min(l.burst, l.tokens+1)
+		l.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// rateLimitTransport waits on the shared limiter before every request
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
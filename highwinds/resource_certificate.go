@@ -5,21 +5,21 @@ import (
 	"strconv"
 
 	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/openwurl/wurlwind/striketracker"
 	"github.com/openwurl/wurlwind/striketracker/models"
 	"github.com/openwurl/wurlwind/striketracker/services/certificates"
 )
 
 func resourceCertificate() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceCertificateCreate,
-		Read:   resourceCertificateRead,
-		Update: resourceCertificateUpdate,
-		Delete: resourceCertificateDelete,
-		Exists: resourceCertificateExists,
+		Create:   resourceCertificateCreate,
+		Read:     resourceCertificateRead,
+		Update:   resourceCertificateUpdate,
+		Delete:   resourceCertificateDelete,
+		Exists:   resourceCertificateExists,
+		Timeouts: resourceTimeouts(),
 		Importer: &schema.ResourceImporter{
 			State: func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				accountHash, resourceID, err := ResourceImportParseHashID(d.Id())
+				accountHash, resourceID, err := ResourceImportParseHashID(d.Id(), meta.(*Meta).AccountHash)
 				if err != nil {
 					return nil, err
 				}
@@ -76,7 +76,8 @@ func resourceCertificate() *schema.Resource {
 			"account_hash": &schema.Schema{
 				Description: "The destination account hash where the origin will be created",
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 			},
 			"issuer": &schema.Schema{
 				Description: "The organization which issued the certificate",
@@ -105,6 +106,12 @@ func resourceCertificate() *schema.Resource {
 				Type:        schema.TypeString,
 				Computed:    true,
 			},
+			"deletion_protection": &schema.Schema{
+				Description: "Refuse to delete this certificate while true",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 		},
 	}
 }
@@ -113,12 +120,12 @@ func resourceCertificate() *schema.Resource {
 	Read
 */
 func resourceCertificateRead(d *schema.ResourceData, m interface{}) error {
-	c := m.(*striketracker.Client)
-	accountHash := d.Get("account_hash").(string)
-
-	cs := certificates.New(c)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
 	defer cancel()
 
 	certificateID, err := strconv.Atoi(d.Id())
@@ -126,9 +133,9 @@ func resourceCertificateRead(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
-	certResource, err := cs.Get(ctx, accountHash, certificateID)
+	certResource, err := m.(*Meta).getCertificate(ctx, accountHash, certificateID)
 	if err != nil {
-		return err
+		return removeIfNotFound(d, "striketracker_certificate", err)
 	}
 
 	d.Set("ca_bundle", certResource.CABundle)
@@ -140,7 +147,10 @@ func resourceCertificateRead(d *schema.ResourceData, m interface{}) error {
 	d.Set("expiration_date", certResource.ExpirationDate)
 	d.Set("fingerprint", certResource.Fingerprint)
 	d.Set("issuer", certResource.Issuer)
-	d.Set("key", certResource.Key)
+	// The account listing leaves out the private key, keep the one in state
+	if certResource.Key != "" {
+		d.Set("key", certResource.Key)
+	}
 	d.Set("requester", certResource.Requester)
 	d.Set("trusted", certResource.Trusted)
 	d.Set("updated_date", certResource.UpdatedDate)
@@ -152,13 +162,20 @@ func resourceCertificateRead(d *schema.ResourceData, m interface{}) error {
 	Create
 */
 func resourceCertificateCreate(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "create", "striketracker_certificate"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
-	c := m.(*striketracker.Client)
-	accountHash := d.Get("account_hash").(string)
+	c := m.(*Meta).Client
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 
 	cs := certificates.New(c)
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutCreate)
 	defer cancel()
 
 	certificate := &models.Certificate{
@@ -170,12 +187,21 @@ func resourceCertificateCreate(d *schema.ResourceData, m interface{}) error {
 		Key:         d.Get("key").(string),
 	}
 
+	ctx = withStatusRecorder(ctx)
 	returnedCertificate, err := cs.Upload(ctx, accountHash, certificate)
+	err = wrapAPIError(ctx, err)
 	if returnedCertificate != nil {
 		if returnedCertificate.ID != 0 {
 			d.SetId(fmt.Sprintf("%d", returnedCertificate.ID))
 		}
 	}
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "create",
+		ResourceType: "striketracker_certificate",
+		AccountHash:  accountHash,
+		ResourceID:   d.Id(),
+		Model:        certificate,
+	}, err)
 	if err != nil {
 		return err
 	}
@@ -190,13 +216,25 @@ func resourceCertificateCreate(d *schema.ResourceData, m interface{}) error {
 	Update
 */
 func resourceCertificateUpdate(d *schema.ResourceData, m interface{}) error {
+	// deletion_protection never reaches the API
+	if onlyLocalChanges(d, resourceCertificate().Schema) {
+		return resourceCertificateRead(d, m)
+	}
+
+	if err := checkWritable(m, "update", "striketracker_certificate"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
-	c := m.(*striketracker.Client)
-	accountHash := d.Get("account_hash").(string)
+	c := m.(*Meta).Client
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 
 	cs := certificates.New(c)
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutUpdate)
 	defer cancel()
 
 	certificateID, err := strconv.Atoi(d.Id())
@@ -214,7 +252,17 @@ func resourceCertificateUpdate(d *schema.ResourceData, m interface{}) error {
 		Key:         d.Get("key").(string),
 	}
 
+	ctx = withStatusRecorder(ctx)
 	returnedCertificate, err := cs.Update(ctx, accountHash, certificate)
+	err = wrapAPIError(ctx, err)
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "update",
+		ResourceType: "striketracker_certificate",
+		AccountHash:  accountHash,
+		ResourceID:   d.Id(),
+		Model:        certificate,
+	}, err)
+	m.(*Meta).certificates.forget(accountHash, certificateID)
 	if returnedCertificate != nil {
 		if returnedCertificate.ID != 0 {
 			d.SetId(fmt.Sprintf("%d", returnedCertificate.ID))
@@ -233,13 +281,23 @@ func resourceCertificateUpdate(d *schema.ResourceData, m interface{}) error {
 	Delete
 */
 func resourceCertificateDelete(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "delete", "striketracker_certificate"); err != nil {
+		return err
+	}
+	if err := checkDeletionProtection(d, m, "striketracker_certificate"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
-	c := m.(*striketracker.Client)
-	accountHash := d.Get("account_hash").(string)
+	c := m.(*Meta).Client
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 
 	cs := certificates.New(c)
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutDelete)
 	defer cancel()
 
 	certificateID, err := strconv.Atoi(d.Id())
@@ -247,8 +305,16 @@ func resourceCertificateDelete(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
-	err = cs.Delete(ctx, accountHash, certificateID)
-	if err != nil {
+	ctx = withStatusRecorder(ctx)
+	err = wrapAPIError(ctx, cs.Delete(ctx, accountHash, certificateID))
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "delete",
+		ResourceType: "striketracker_certificate",
+		AccountHash:  accountHash,
+		ResourceID:   d.Id(),
+	}, err)
+	m.(*Meta).certificates.forget(accountHash, certificateID)
+	if err != nil && !IsNotFound(err) {
 		return err
 	}
 
@@ -261,12 +327,12 @@ func resourceCertificateDelete(d *schema.ResourceData, m interface{}) error {
 	Exists
 */
 func resourceCertificateExists(d *schema.ResourceData, m interface{}) (bool, error) {
-	c := m.(*striketracker.Client)
-	accountHash := d.Get("account_hash").(string)
-
-	cs := certificates.New(c)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return false, err
+	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
 	defer cancel()
 
 	certificateID, err := strconv.Atoi(d.Id())
@@ -274,14 +340,13 @@ func resourceCertificateExists(d *schema.ResourceData, m interface{}) (bool, err
 		return false, err
 	}
 
-	certResource, err := cs.Get(ctx, accountHash, certificateID)
+	_, err = m.(*Meta).getCertificate(ctx, accountHash, certificateID)
+	if IsNotFound(err) {
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
 
-	if certResource == nil {
-		return false, fmt.Errorf(striketracker.ErrNotFound)
-	}
-
 	return true, nil
 }
@@ -538,15 +538,17 @@ func resourceConfiguration() *schema.Resource {
 	}
 
 	return &schema.Resource{
-		Create: resourceConfigurationCreate,
-		Read:   resourceConfigurationRead,
-		Update: resourceConfigurationUpdate,
-		Delete: resourceConfigurationDelete,
-		Exists: resourceConfigurationExists,
+		Create:        resourceConfigurationCreate,
+		Read:          resourceConfigurationRead,
+		Update:        resourceConfigurationUpdate,
+		Delete:        resourceConfigurationDelete,
+		Exists:        resourceConfigurationExists,
+		Timeouts:      resourceTimeouts(),
+		CustomizeDiff: warnOutsideChangeWindow("striketracker_configuration"),
 		Importer: &schema.ResourceImporter{
 			State: func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 				// TODO: Importer - Ensure this actually works and expand as needed
-				accountHash, hostHash, scopeID, err := ResourceConfigurationParseHashID(d.Id())
+				accountHash, hostHash, scopeID, err := ResourceConfigurationParseHashID(d.Id(), meta.(*Meta).AccountHash)
 				if err != nil {
 					return nil, err
 				}
@@ -561,7 +563,8 @@ func resourceConfiguration() *schema.Resource {
 			"account_hash": &schema.Schema{
 				Description: "The destination account hash where the origin will be created",
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 			},
 			"host_hash": &schema.Schema{
 				Description: "The hash code of the parent host this scope is being attached to",
@@ -601,6 +604,12 @@ func resourceConfiguration() *schema.Resource {
 			"client_response_edge_rule": requestModificationsSchema,
 			"delivery":                  deliverySchema,
 			"origin":                    originSchema,
+			"deletion_protection": &schema.Schema{
+				Description: "Refuse to delete this configuration scope while true",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 		},
 	}
 }
@@ -2,11 +2,11 @@ package highwinds
 
 import (
 	"fmt"
+	"log"
 	"strconv"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/openwurl/wurlwind/pkg/debug"
-	"github.com/openwurl/wurlwind/striketracker"
 	"github.com/openwurl/wurlwind/striketracker/services/configuration"
 )
 
@@ -14,14 +14,24 @@ import (
 	Create
 */
 func resourceConfigurationCreate(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "create", "striketracker_configuration"); err != nil {
+		return err
+	}
+	if err := checkChangeWindow(m, "create", "striketracker_configuration"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
 
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 	conf := configuration.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 	hostHash := d.Get("host_hash").(string)
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutCreate)
 	defer cancel()
 
 	// Build our model to send
@@ -31,12 +41,23 @@ func resourceConfigurationCreate(d *schema.ResourceData, m interface{}) error {
 	}
 
 	// Send model
+	ctx = withStatusRecorder(ctx)
 	returnedModel, err := conf.Create(ctx, accountHash, hostHash, newConfigurationScope)
+	err = wrapAPIError(ctx, err)
+	m.(*Meta).invalidateHost(accountHash, hostHash)
 	if returnedModel != nil {
 		if returnedModel.ID != 0 {
 			d.SetId(fmt.Sprintf("%d", returnedModel.ID))
 		}
 	}
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "create",
+		ResourceType: "striketracker_configuration",
+		AccountHash:  accountHash,
+		HostHash:     hostHash,
+		ScopeID:      d.Id(),
+		Model:        newConfigurationScope,
+	}, err)
 	if err != nil {
 		return err
 	}
@@ -50,15 +71,30 @@ func resourceConfigurationCreate(d *schema.ResourceData, m interface{}) error {
 	Create
 */
 func resourceConfigurationUpdate(d *schema.ResourceData, m interface{}) error {
-	c := m.(*striketracker.Client)
+	// deletion_protection never reaches the API
+	if onlyLocalChanges(d, resourceConfiguration().Schema) {
+		return resourceConfigurationRead(d, m)
+	}
+
+	if err := checkWritable(m, "update", "striketracker_configuration"); err != nil {
+		return err
+	}
+	if err := checkChangeWindow(m, "update", "striketracker_configuration"); err != nil {
+		return err
+	}
+
+	c := m.(*Meta).Client
 	conf := configuration.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 	hostHash := d.Get("host_hash").(string)
 	scopeID, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return err
 	}
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutUpdate)
 	defer cancel()
 
 	debug.Log("Update", "Preparing to update configuration %s/%s/%d", accountHash, hostHash, scopeID)
@@ -69,9 +105,35 @@ func resourceConfigurationUpdate(d *schema.ResourceData, m interface{}) error {
 		return fmt.Errorf("Error building config from state: %v", err.Error())
 	}
 
+	// Diff against the current remote model for the audit journal. A scope
+	// just created is journalled by the create and may not read back yet, and
+	// a failed lookup only costs the diff, never the write
+	var changes []auditChange
+	if m.(*Meta).auditEnabled() && !d.IsNewResource() {
+		getCtx := withStatusRecorder(ctx)
+		previousModel, err := conf.Get(getCtx, accountHash, hostHash, scopeID)
+		if err = wrapAPIError(getCtx, err); err != nil {
+			log.Printf("[WARN] Reading configuration %s/%s/%d for the audit diff failed, journalling without it: %v", accountHash, hostHash, scopeID, err)
+		} else {
+			changes = diffModels(previousModel, newConfigurationScope)
+		}
+	}
+
 	debug.Log("Update", "Updating configuration %s/%s/%d", accountHash, hostHash, scopeID)
 	// Ship object
+	ctx = withStatusRecorder(ctx)
 	returnedModel, err := conf.Update(ctx, accountHash, hostHash, scopeID, newConfigurationScope)
+	err = wrapAPIError(ctx, err)
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "update",
+		ResourceType: "striketracker_configuration",
+		AccountHash:  accountHash,
+		HostHash:     hostHash,
+		ScopeID:      d.Id(),
+		Model:        newConfigurationScope,
+		Diff:         changes,
+	}, err)
+	m.(*Meta).invalidateHost(accountHash, hostHash)
 	if err != nil {
 		return err
 	}
@@ -79,6 +141,10 @@ func resourceConfigurationUpdate(d *schema.ResourceData, m interface{}) error {
 		return fmt.Errorf("Something went wrong updating the scope %s, returned model is nil", d.Id())
 	}
 
+	if err := m.(*Meta).waitForConfiguration(ctx, accountHash, hostHash, scopeID); err != nil {
+		return err
+	}
+
 	d.Partial(false)
 
 	return resourceConfigurationRead(d, m)
@@ -88,31 +154,32 @@ func resourceConfigurationUpdate(d *schema.ResourceData, m interface{}) error {
 	Create
 */
 func resourceConfigurationRead(d *schema.ResourceData, m interface{}) error {
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 	conf := configuration.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 	hostHash := d.Get("host_hash").(string)
 	scopeID, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return err
 	}
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
 	defer cancel()
 
 	debug.Log("Read", "Reading configuration %s/%s/%d", accountHash, hostHash, scopeID)
 
 	// Fetch resource
+	ctx = withStatusRecorder(ctx)
 	configModel, err := conf.Get(ctx, accountHash, hostHash, scopeID)
-	if err != nil {
-		return err
-	}
-	if configModel == nil {
-		return fmt.Errorf("Resource %s does not exist", d.Id())
+	if err := lookupError(ctx, err, configModel != nil, "configuration", d.Id()); err != nil {
+		return removeIfNotFound(d, "striketracker_configuration", err)
 	}
 
 	debug.Log("Read", "Setting configuration state %s/%s/%d", accountHash, hostHash, scopeID)
 
-	if configModel.Platform == "" || configModel.ID == 0 || configModel.Path == "" {
+	if !configurationComplete(configModel) {
 		return ErrScopeIsNil(accountHash, hostHash, scopeID)
 	}
 
@@ -131,20 +198,42 @@ func resourceConfigurationRead(d *schema.ResourceData, m interface{}) error {
 	Delete
 */
 func resourceConfigurationDelete(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "delete", "striketracker_configuration"); err != nil {
+		return err
+	}
+	if err := checkChangeWindow(m, "delete", "striketracker_configuration"); err != nil {
+		return err
+	}
+	if err := checkDeletionProtection(d, m, "striketracker_configuration"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 	conf := configuration.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 	hostHash := d.Get("host_hash").(string)
 	scopeID, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return err
 	}
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutDelete)
 	defer cancel()
 
-	err = conf.Delete(ctx, accountHash, hostHash, scopeID, false)
-	if err != nil {
+	ctx = withStatusRecorder(ctx)
+	err = wrapAPIError(ctx, conf.Delete(ctx, accountHash, hostHash, scopeID, false))
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "delete",
+		ResourceType: "striketracker_configuration",
+		AccountHash:  accountHash,
+		HostHash:     hostHash,
+		ScopeID:      d.Id(),
+	}, err)
+	m.(*Meta).invalidateHost(accountHash, hostHash)
+	if err != nil && !IsNotFound(err) {
 		return err
 	}
 
@@ -153,31 +242,35 @@ func resourceConfigurationDelete(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-// TODO: exists
 /*
 	Exists
 */
 func resourceConfigurationExists(d *schema.ResourceData, m interface{}) (bool, error) {
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 	conf := configuration.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return false, err
+	}
 	hostHash := d.Get("host_hash").(string)
 	scopeID, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return false, err
 	}
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
 	defer cancel()
 
 	debug.Log("Exists", "Reading configuration %s/%s/%d", accountHash, hostHash, scopeID)
 
 	// Fetch resource
+	ctx = withStatusRecorder(ctx)
 	configModel, err := conf.Get(ctx, accountHash, hostHash, scopeID)
+	err = lookupError(ctx, err, configModel != nil, "configuration", d.Id())
+	if IsNotFound(err) {
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
-	if configModel == nil {
-		return false, fmt.Errorf("Resource %s does not exist", d.Id())
-	}
 	return true, nil
 }
@@ -6,44 +6,47 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/openwurl/wurlwind/pkg/debug"
-	"github.com/openwurl/wurlwind/striketracker"
-	"github.com/openwurl/wurlwind/striketracker/services/hosts"
 )
 
 func defaultResourceConfiguration() *schema.Resource {
 	drc := resourceConfiguration()
 	drc.Create = resourceDefaultConfigurationCreate
 	drc.Delete = resourceDefaultConfigurationDelete
+	drc.CustomizeDiff = warnOutsideChangeWindow("striketracker_default_configuration")
 
 	return drc
 }
 
 func resourceDefaultConfigurationCreate(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "create", "striketracker_default_configuration"); err != nil {
+		return err
+	}
+	if err := checkChangeWindow(m, "create", "striketracker_default_configuration"); err != nil {
+		return err
+	}
+
 	// Fetch defined host
-	c := m.(*striketracker.Client)
-	h := hosts.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 	hostHash := d.Get("host_hash").(string)
 
 	debug.Log("Fetch", "Fetching %s/%s", accountHash, hostHash)
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutCreate)
 	defer cancel()
 
-	hostResource, err := h.Get(ctx, accountHash, hostHash)
+	hostResource, err := m.(*Meta).getHost(ctx, accountHash, hostHash)
 	if err != nil {
 		return err
 	}
 
-	if hostResource == nil {
-		return fmt.Errorf("Resource %s does not exist", d.Id())
-	}
-
 	// Extract root scope
 
 	rootScope := hostResource.GetCDSScope()
 	if rootScope == nil {
-		return fmt.Errorf("Could not fetch Root Scope on parent host: %v", rootScope.Name)
+		return fmt.Errorf("Could not fetch Root Scope on parent host: %v", hostResource.Name)
 	}
 
 	// Set ID from root scope
@@ -56,6 +59,13 @@ func resourceDefaultConfigurationCreate(d *schema.ResourceData, m interface{}) e
 }
 
 func resourceDefaultConfigurationDelete(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "delete", "striketracker_default_configuration"); err != nil {
+		return err
+	}
+	if err := checkDeletionProtection(d, m, "striketracker_default_configuration"); err != nil {
+		return err
+	}
+
 	log.Printf("[WARN] Cannot destroy Default Scope Configuration. Terraform will remove this resource from the state file, however resources may remain.")
 	return nil
 }
@@ -5,7 +5,6 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/openwurl/wurlwind/pkg/debug"
-	"github.com/openwurl/wurlwind/striketracker"
 	"github.com/openwurl/wurlwind/striketracker/models"
 	"github.com/openwurl/wurlwind/striketracker/services/hosts"
 )
@@ -47,14 +46,16 @@ func resourceHost() *schema.Resource {
 	}
 
 	return &schema.Resource{
-		Create: resourceHostCreate,
-		Read:   resourceHostRead,
-		Update: resourceHostUpdate,
-		Delete: resourceHostDelete,
-		Exists: resourceHostExists,
+		Create:        resourceHostCreate,
+		Read:          resourceHostRead,
+		Update:        resourceHostUpdate,
+		Delete:        resourceHostDelete,
+		Exists:        resourceHostExists,
+		Timeouts:      resourceTimeouts(),
+		CustomizeDiff: warnOutsideChangeWindow("striketracker_host"),
 		Importer: &schema.ResourceImporter{
 			State: func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				accountHash, resourceID, err := ResourceImportParseHashID(d.Id())
+				accountHash, resourceID, err := ResourceImportParseHashID(d.Id(), meta.(*Meta).AccountHash)
 				if err != nil {
 					return nil, err
 				}
@@ -68,7 +69,8 @@ func resourceHost() *schema.Resource {
 			"account_hash": &schema.Schema{
 				Description: "The destination account hash where the origin will be created",
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 			},
 			"name": &schema.Schema{
 				Description: "The name of the host",
@@ -93,6 +95,18 @@ func resourceHost() *schema.Resource {
 				Computed:    true,
 				Optional:    false,
 			},
+			"adopt_existing": &schema.Schema{
+				Description: "Take ownership of an existing host with the same name instead of creating a duplicate",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"deletion_protection": &schema.Schema{
+				Description: "Refuse to delete this host while true",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 		},
 	}
 }
@@ -101,11 +115,21 @@ func resourceHost() *schema.Resource {
 	Create
 */
 func resourceHostCreate(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "create", "striketracker_host"); err != nil {
+		return err
+	}
+	if err := checkChangeWindow(m, "create", "striketracker_host"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
 
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 	h := hosts.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 
 	host := &models.Host{
 		Name: d.Get("name").(string),
@@ -121,22 +145,49 @@ func resourceHostCreate(d *schema.ResourceData, m interface{}) error {
 		}
 	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutCreate)
 	defer cancel()
 
+	// A create that timed out may still have succeeded, look before creating a duplicate
+	if d.Get("adopt_existing").(bool) {
+		existing, err := m.(*Meta).findHostByName(ctx, accountHash, host.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			debug.Log("Create", "Adopting existing host %s (%s)", existing.Name, existing.HashCode)
+			d.SetId(existing.HashCode)
+			d.Partial(false)
+			return resourceHostUpdate(d, m)
+		}
+	}
+
 	debug.Log("Create", "Creating host %s", host.Name)
 
+	ctx = withStatusRecorder(ctx)
 	returnedModel, err := h.Create(ctx, accountHash, host)
+	err = wrapAPIError(ctx, err)
 	if returnedModel != nil {
 		if returnedModel.HashCode != "" {
 			d.SetId(returnedModel.HashCode)
-			d.Set("root_scope_id", fmt.Sprintf("%d", returnedModel.GetCDSScope().ID))
+			d.Set("root_scope_id", rootScopeID(returnedModel))
 		}
 	}
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "create",
+		ResourceType: "striketracker_host",
+		AccountHash:  accountHash,
+		HostHash:     d.Id(),
+		Model:        host,
+	}, err)
 	if err != nil {
 		return err
 	}
 
+	if err := m.(*Meta).waitForHost(ctx, accountHash, d.Id()); err != nil {
+		return err
+	}
+
 	d.Partial(false)
 
 	return resourceHostRead(d, m)
@@ -146,10 +197,25 @@ func resourceHostCreate(d *schema.ResourceData, m interface{}) error {
 	Update
 */
 func resourceHostUpdate(d *schema.ResourceData, m interface{}) error {
+	// deletion_protection and adopt_existing never reach the API
+	if onlyLocalChanges(d, resourceHost().Schema) {
+		return resourceHostRead(d, m)
+	}
+
+	if err := checkWritable(m, "update", "striketracker_host"); err != nil {
+		return err
+	}
+	if err := checkChangeWindow(m, "update", "striketracker_host"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 	h := hosts.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 
 	host := &models.Host{
 		Name: d.Get("name").(string),
@@ -165,20 +231,34 @@ func resourceHostUpdate(d *schema.ResourceData, m interface{}) error {
 		}
 	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutUpdate)
 	defer cancel()
 
+	ctx = withStatusRecorder(ctx)
 	returnedModel, err := h.Update(ctx, accountHash, d.Id(), host)
+	err = wrapAPIError(ctx, err)
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "update",
+		ResourceType: "striketracker_host",
+		AccountHash:  accountHash,
+		HostHash:     d.Id(),
+		Model:        host,
+	}, err)
+	m.(*Meta).invalidateHost(accountHash, d.Id())
 	if returnedModel != nil {
 		if returnedModel.HashCode != "" {
 			d.SetId(returnedModel.HashCode)
-			d.Set("root_scope_id", fmt.Sprintf("%d", returnedModel.GetCDSScope().ID))
+			d.Set("root_scope_id", rootScopeID(returnedModel))
 		}
 	}
 	if err != nil {
 		return err
 	}
 
+	if err := m.(*Meta).waitForHost(ctx, accountHash, d.Id()); err != nil {
+		return err
+	}
+
 	d.Partial(false)
 	return resourceHostRead(d, m)
 }
@@ -187,16 +267,37 @@ func resourceHostUpdate(d *schema.ResourceData, m interface{}) error {
 	Delete
 */
 func resourceHostDelete(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "delete", "striketracker_host"); err != nil {
+		return err
+	}
+	if err := checkChangeWindow(m, "delete", "striketracker_host"); err != nil {
+		return err
+	}
+	if err := checkDeletionProtection(d, m, "striketracker_host"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 	h := hosts.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutDelete)
 	defer cancel()
 
-	err := h.Delete(ctx, accountHash, d.Id())
-	if err != nil {
+	ctx = withStatusRecorder(ctx)
+	err = wrapAPIError(ctx, h.Delete(ctx, accountHash, d.Id()))
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "delete",
+		ResourceType: "striketracker_host",
+		AccountHash:  accountHash,
+		HostHash:     d.Id(),
+	}, err)
+	m.(*Meta).invalidateHost(accountHash, d.Id())
+	if err != nil && !IsNotFound(err) {
 		return err
 	}
 	d.Partial(false)
@@ -208,56 +309,77 @@ func resourceHostDelete(d *schema.ResourceData, m interface{}) error {
 	Read
 */
 func resourceHostRead(d *schema.ResourceData, m interface{}) error {
-	c := m.(*striketracker.Client)
-	h := hosts.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
 	defer cancel()
 
 	debug.Log("Read", "Reading host %s", d.Id())
 
-	hostResource, err := h.Get(ctx, accountHash, d.Id())
+	hostResource, err := m.(*Meta).getHost(ctx, accountHash, d.Id())
 	if err != nil {
-		return err
+		return removeIfNotFound(d, "striketracker_host", err)
 	}
 
-	if hostResource == nil {
-		return fmt.Errorf("Resource %s does not exist", d.Id())
-	}
+	return setHostState(d, hostResource)
+}
 
-	d.Set("root_scope_id", fmt.Sprintf("%d", hostResource.GetCDSScope().ID))
-	d.Set("name", hostResource.Name)
-	d.Set("hash_code", hostResource.HashCode)
-	d.Set("services", hostResource.Services)
+// setHostState copies the remote host into state
+func setHostState(d *schema.ResourceData, hostResource *models.Host) error {
+	serviceIDs := make([]int, 0, len(hostResource.Services))
+	for _, service := range hostResource.Services {
+		if service != nil {
+			serviceIDs = append(serviceIDs, service.ID)
+		}
+	}
 
-	scopesList := buildScopesList(hostResource.Scopes)
-	if err := d.Set("scopes", scopesList); err != nil {
-		return fmt.Errorf("error setting scopes on %s: %v", hostResource.Name, err)
+	var errs []error
+	for key, value := range map[string]interface{}{
+		"root_scope_id": rootScopeID(hostResource),
+		"name":          hostResource.Name,
+		"hash_code":     hostResource.HashCode,
+		"services":      serviceIDs,
+		"scopes":        buildScopesList(hostResource.Scopes),
+		"type":          hostResource.Type,
+	} {
+		if err := d.Set(key, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", key, err))
+		}
 	}
-	d.Set("type", hostResource.Type)
 
-	return nil
+	return ErrSetState(errs)
+}
+
+// rootScopeID returns the ID of the host's CDS scope, empty when the host has none
+func rootScopeID(hostResource *models.Host) string {
+	rootScope := hostResource.GetCDSScope()
+	if rootScope == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", rootScope.ID)
 }
 
 /*
 	Exists
 */
 func resourceHostExists(d *schema.ResourceData, m interface{}) (bool, error) {
-	c := m.(*striketracker.Client)
-	h := hosts.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return false, err
+	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
 	defer cancel()
 
-	hostResource, err := h.Get(ctx, accountHash, d.Id())
-	if err != nil {
+	_, err = m.(*Meta).getHost(ctx, accountHash, d.Id())
+	if IsNotFound(err) {
 		return false, nil
 	}
-
-	if hostResource == nil {
-		return false, nil
+	if err != nil {
+		return false, err
 	}
 
 	return true, nil
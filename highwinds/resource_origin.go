@@ -4,23 +4,23 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/openwurl/wurlwind/striketracker"
-
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/openwurl/wurlwind/pkg/debug"
 	"github.com/openwurl/wurlwind/striketracker/models"
 	"github.com/openwurl/wurlwind/striketracker/services/origin"
 )
 
 func resourceOrigin() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceOriginCreate,
-		Read:   resourceOriginRead,
-		Update: resourceOriginUpdate,
-		Delete: resourceOriginDelete,
-		Exists: resourceOriginExists,
+		Create:   resourceOriginCreate,
+		Read:     resourceOriginRead,
+		Update:   resourceOriginUpdate,
+		Delete:   resourceOriginDelete,
+		Exists:   resourceOriginExists,
+		Timeouts: resourceTimeouts(),
 		Importer: &schema.ResourceImporter{
 			State: func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				accountHash, resourceID, err := ResourceImportParseHashID(d.Id())
+				accountHash, resourceID, err := ResourceImportParseHashID(d.Id(), meta.(*Meta).AccountHash)
 				if err != nil {
 					return nil, err
 				}
@@ -50,7 +50,8 @@ func resourceOrigin() *schema.Resource {
 			"account_hash": &schema.Schema{
 				Description: "The destination account hash where the origin will be created",
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 			},
 			"authentication_type": &schema.Schema{
 				Description: "Authentication type, NONE or BASIC",
@@ -133,6 +134,18 @@ func resourceOrigin() *schema.Resource {
 				Type:        schema.TypeBool,
 				Optional:    true,
 			},
+			"adopt_existing": &schema.Schema{
+				Description: "Take ownership of an existing origin with the same name instead of creating a duplicate",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"deletion_protection": &schema.Schema{
+				Description: "Refuse to delete this origin while true",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 		},
 	}
 }
@@ -141,11 +154,18 @@ func resourceOrigin() *schema.Resource {
 	Create
 */
 func resourceOriginCreate(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "create", "striketracker_origin"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
 
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 	s := origin.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 	origin := &models.Origin{
 		Name:                         d.Get("name").(string),
 		Hostname:                     d.Get("hostname").(string),
@@ -167,15 +187,38 @@ func resourceOriginCreate(d *schema.ResourceData, m interface{}) error {
 		VerifyCertificate:            d.Get("verify_certificate").(bool),
 	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutCreate)
 	defer cancel()
 
+	// A create that timed out may still have succeeded, look before creating a duplicate
+	if d.Get("adopt_existing").(bool) {
+		existing, err := m.(*Meta).findOriginByName(ctx, accountHash, origin.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			debug.Log("Create", "Adopting existing origin %s (%d)", existing.Name, existing.ID)
+			d.SetId(fmt.Sprintf("%d", existing.ID))
+			d.Partial(false)
+			return resourceOriginUpdate(d, m)
+		}
+	}
+
+	ctx = withStatusRecorder(ctx)
 	returnedModel, err := s.Create(ctx, accountHash, origin)
+	err = wrapAPIError(ctx, err)
 	if returnedModel != nil {
 		if returnedModel.ID != 0 {
 			d.SetId(fmt.Sprintf("%d", returnedModel.ID))
 		}
 	}
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "create",
+		ResourceType: "striketracker_origin",
+		AccountHash:  accountHash,
+		ResourceID:   d.Id(),
+		Model:        origin,
+	}, err)
 	if err != nil {
 		return err
 	}
@@ -189,57 +232,84 @@ func resourceOriginCreate(d *schema.ResourceData, m interface{}) error {
 	Read
 */
 func resourceOriginRead(d *schema.ResourceData, m interface{}) error {
-	c := m.(*striketracker.Client)
-	s := origin.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
 	defer cancel()
 
 	originID, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return err
 	}
-	originResource, err := s.Get(ctx, accountHash, originID)
+	originResource, err := m.(*Meta).getOrigin(ctx, accountHash, originID)
 	if err != nil {
-		return err
+		return removeIfNotFound(d, "striketracker_origin", err)
 	}
 
-	d.Set("name", originResource.Name)
-	d.Set("hostname", originResource.Hostname)
-	d.Set("port", originResource.Port)
-	d.Set("path", originResource.Path)
-	d.Set("authentication_type", originResource.AuthenticationType)
-	d.Set("certificate_cn", originResource.CertificateCN)
-	d.Set("error_cache_ttl_seconds", originResource.ErrorCacheTTLSeconds)
-	d.Set("max_connections_per_edge", originResource.MaxConnectionsPerEdge)
-	d.Set("max_connections_per_edge_enabled", originResource.MaxConnectionsPerEdgeEnabled)
-	d.Set("maximum_origin_pull_seconds", originResource.MaximumOriginPullSeconds)
-	d.Set("max_retry_count", originResource.MaxRetryCount)
-	d.Set("origin_cache_headers", originResource.OriginCacheHeaders)
-	d.Set("origin_default_keep_alive", originResource.OriginDefaultKeepAlive)
-	d.Set("origin_pull_headers", originResource.OriginPullHeaders)
-	d.Set("origin_pull_neg_linger", originResource.OriginPullNegLinger)
-	d.Set("request_timeout_seconds", originResource.RequestTimeoutSeconds)
-	d.Set("secure_port", originResource.SecurePort)
-	d.Set("verify_certificate", originResource.VerifyCertificate)
+	setOriginState(d, originResource)
 
 	return nil
 }
 
+// setOriginState copies the remote origin into state
+func setOriginState(d *schema.ResourceData, originResource *models.Origin) {
+	for key, value := range flattenOrigin(originResource) {
+		d.Set(key, value)
+	}
+}
+
+// flattenOrigin maps an origin onto the attributes resourceOrigin reads
+func flattenOrigin(originResource *models.Origin) map[string]interface{} {
+	return map[string]interface{}{
+		"name":                             originResource.Name,
+		"hostname":                         originResource.Hostname,
+		"port":                             originResource.Port,
+		"path":                             originResource.Path,
+		"authentication_type":              originResource.AuthenticationType,
+		"certificate_cn":                   originResource.CertificateCN,
+		"error_cache_ttl_seconds":          originResource.ErrorCacheTTLSeconds,
+		"max_connections_per_edge":         originResource.MaxConnectionsPerEdge,
+		"max_connections_per_edge_enabled": originResource.MaxConnectionsPerEdgeEnabled,
+		"maximum_origin_pull_seconds":      originResource.MaximumOriginPullSeconds,
+		"max_retry_count":                  originResource.MaxRetryCount,
+		"origin_cache_headers":             originResource.OriginCacheHeaders,
+		"origin_default_keep_alive":        originResource.OriginDefaultKeepAlive,
+		"origin_pull_headers":              originResource.OriginPullHeaders,
+		"origin_pull_neg_linger":           originResource.OriginPullNegLinger,
+		"request_timeout_seconds":          originResource.RequestTimeoutSeconds,
+		"secure_port":                      originResource.SecurePort,
+		"verify_certificate":               originResource.VerifyCertificate,
+	}
+}
+
 /*
 	Update
 */
 func resourceOriginUpdate(d *schema.ResourceData, m interface{}) error {
+	// deletion_protection and adopt_existing never reach the API
+	if onlyLocalChanges(d, resourceOrigin().Schema) {
+		return resourceOriginRead(d, m)
+	}
+
+	if err := checkWritable(m, "update", "striketracker_origin"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 	originID, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return fmt.Errorf("Origin ID %s is an invalid origin ID: %v", d.Id(), err)
 	}
 
 	s := origin.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 	origin := &models.Origin{
 		ID:                           originID,
 		Name:                         d.Get("name").(string),
@@ -262,10 +332,20 @@ func resourceOriginUpdate(d *schema.ResourceData, m interface{}) error {
 		VerifyCertificate:            d.Get("verify_certificate").(bool),
 	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutUpdate)
 	defer cancel()
 
+	ctx = withStatusRecorder(ctx)
 	returnedModel, err := s.Update(ctx, accountHash, origin)
+	err = wrapAPIError(ctx, err)
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "update",
+		ResourceType: "striketracker_origin",
+		AccountHash:  accountHash,
+		ResourceID:   d.Id(),
+		Model:        origin,
+	}, err)
+	m.(*Meta).origins.forget(accountHash, originID)
 	if returnedModel != nil {
 		if returnedModel.ID != 0 {
 			d.SetId(fmt.Sprintf("%d", returnedModel.ID))
@@ -283,21 +363,39 @@ func resourceOriginUpdate(d *schema.ResourceData, m interface{}) error {
 	Delete
 */
 func resourceOriginDelete(d *schema.ResourceData, m interface{}) error {
+	if err := checkWritable(m, "delete", "striketracker_origin"); err != nil {
+		return err
+	}
+	if err := checkDeletionProtection(d, m, "striketracker_origin"); err != nil {
+		return err
+	}
+
 	d.Partial(true)
-	c := m.(*striketracker.Client)
+	c := m.(*Meta).Client
 
 	s := origin.New(c)
-	accountHash := d.Get("account_hash").(string)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return err
+	}
 	originID, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return fmt.Errorf("Origin ID %s is an invalid origin ID: %v", d.Id(), err)
 	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutDelete)
 	defer cancel()
 
-	err = s.Delete(ctx, accountHash, originID)
-	if err != nil {
+	ctx = withStatusRecorder(ctx)
+	err = wrapAPIError(ctx, s.Delete(ctx, accountHash, originID))
+	m.(*Meta).auditCall(ctx, &auditEntry{
+		Action:       "delete",
+		ResourceType: "striketracker_origin",
+		AccountHash:  accountHash,
+		ResourceID:   d.Id(),
+	}, err)
+	m.(*Meta).origins.forget(accountHash, originID)
+	if err != nil && !IsNotFound(err) {
 		return err
 	}
 	d.Partial(false)
@@ -309,12 +407,12 @@ func resourceOriginDelete(d *schema.ResourceData, m interface{}) error {
 	Exists
 */
 func resourceOriginExists(d *schema.ResourceData, m interface{}) (bool, error) {
-	c := m.(*striketracker.Client)
-	accountHash := d.Get("account_hash").(string)
-
-	cs := origin.New(c)
+	accountHash, err := getAccountHash(d, m)
+	if err != nil {
+		return false, err
+	}
 
-	ctx, cancel := getContext()
+	ctx, cancel := getContext(d, m, schema.TimeoutRead)
 	defer cancel()
 
 	originID, err := strconv.Atoi(d.Id())
@@ -322,14 +420,13 @@ func resourceOriginExists(d *schema.ResourceData, m interface{}) (bool, error) {
 		return false, err
 	}
 
-	originResource, err := cs.Get(ctx, accountHash, originID)
+	_, err = m.(*Meta).getOrigin(ctx, accountHash, originID)
+	if IsNotFound(err) {
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
 
-	if originResource == nil {
-		return false, fmt.Errorf(striketracker.ErrNotFound)
-	}
-
 	return true, nil
 }
@@ -0,0 +1,189 @@
+package highwinds
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Retry defaults
+const (
+	DefaultMaxRetries   = 4
+	DefaultRetryMaxWait = 30 * time.Second
+
+	// retryBaseWait is the first backoff interval, doubled on every attempt
+	retryBaseWait = 1 * time.Second
+)
+
+// retryTransport retries throttled and transient API failures with
+// exponential backoff and jitter, honouring Retry-After
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	maxWait    time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		r, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(r)
+		if attempt >= t.maxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		// A body we cannot replay means we cannot safely send it again
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			log.Printf("[DEBUG] %s %s returned %d, retrying in %s (attempt %d of %d)", req.Method, req.URL.Path, resp.StatusCode, wait, attempt+1, t.maxRetries)
+		} else {
+			log.Printf("[DEBUG] %s %s failed: %v, retrying in %s (attempt %d of %d)", req.Method, req.URL.Path, err, wait, attempt+1, t.maxRetries)
+		}
+
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns how long to wait before the next attempt
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if wait > t.maxWait {
+				return t.maxWait
+			}
+			return wait
+		}
+	}
+
+	wait := retryBaseWait << uint(attempt)
+	if wait <= 0 || wait > t.maxWait {
+		wait = t.maxWait
+	}
+
+	// Jitter between half and the full interval so parallel resources spread out
+	half := int64(wait / 2)
+	if half <= 0 {
+		return wait
+	}
+	return time.Duration(half + rand.Int63n(half))
+}
+
+// shouldRetry decides whether a failed attempt can be sent again
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		// A failed dial never reached the server so any method is safe
+		if isDialError(err) {
+			return true
+		}
+		return isIdempotent(req.Method) && isTransientError(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		// Throttled requests were rejected before being processed
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(req.Method)
+	}
+
+	return false
+}
+
+// rewindRequest returns the request to send for the given attempt
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// isIdempotent reports whether repeating the method has no additional effect
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isDialError reports whether the connection could not be established at all
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isTransientError reports whether a transport error is worth retrying
+func isTransientError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or HTTP date form
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until the context is done
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
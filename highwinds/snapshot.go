@@ -0,0 +1,137 @@
+package highwinds
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/openwurl/wurlwind/striketracker/models"
+	"github.com/openwurl/wurlwind/striketracker/services/certificates"
+	"github.com/openwurl/wurlwind/striketracker/services/origin"
+)
+
+// listSnapshot holds the result of one list call per account so individual
+// reads during a refresh do not each need their own round trip
+type listSnapshot struct {
+	mu       sync.Mutex
+	accounts map[string]*snapshotEntry
+}
+
+// snapshotEntry is a loaded or in-flight account listing
+type snapshotEntry struct {
+	ready chan struct{}
+	items map[int]interface{}
+	stale map[int]bool
+}
+
+func newListSnapshot() *listSnapshot {
+	return &listSnapshot{
+		accounts: map[string]*snapshotEntry{},
+	}
+}
+
+// lookup returns the item from the account snapshot, listing the account on
+// first access. A miss or a failed listing reports false so the caller can
+// fall back to a direct GET
+func (s *listSnapshot) lookup(ctx context.Context, accountHash string, id int, list func() (map[int]interface{}, error)) (interface{}, bool) {
+	s.mu.Lock()
+	entry, ok := s.accounts[accountHash]
+	if !ok {
+		entry = &snapshotEntry{
+			ready: make(chan struct{}),
+			stale: map[int]bool{},
+		}
+		s.accounts[accountHash] = entry
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		items, err := list()
+		if err != nil {
+			log.Printf("[DEBUG] Listing account %s failed, falling back to direct reads: %v", accountHash, err)
+		}
+
+		s.mu.Lock()
+		for staleID := range entry.stale {
+			delete(items, staleID)
+		}
+		entry.items = items
+		s.mu.Unlock()
+		close(entry.ready)
+	}
+
+	select {
+	case <-entry.ready:
+	case <-ctx.Done():
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, found := entry.items[id]
+	return item, found
+}
+
+// forget drops an item from the snapshot after it has been written to
+func (s *listSnapshot) forget(accountHash string, id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.accounts[accountHash]; ok {
+		entry.stale[id] = true
+		delete(entry.items, id)
+	}
+}
+
+// getOrigin reads an origin from the account snapshot, or directly when it is missing
+func (m *Meta) getOrigin(ctx context.Context, accountHash string, originID int) (*models.Origin, error) {
+	item, _ := m.origins.lookup(ctx, accountHash, originID, func() (map[int]interface{}, error) {
+		originList, err := m.listOrigins(ctx, accountHash)
+		if err != nil {
+			return nil, err
+		}
+		items := map[int]interface{}{}
+		for _, o := range originList {
+			items[o.ID] = o
+		}
+		return items, nil
+	})
+	if o, ok := item.(*models.Origin); ok && o != nil {
+		return o, nil
+	}
+
+	ctx = withStatusRecorder(ctx)
+	o, err := origin.New(m.Client).Get(ctx, accountHash, originID)
+	if err := lookupError(ctx, err, o != nil, "origin", strconv.Itoa(originID)); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// getCertificate reads a certificate from the account snapshot, or directly when it is missing
+func (m *Meta) getCertificate(ctx context.Context, accountHash string, certificateID int) (*models.Certificate, error) {
+	cs := certificates.New(m.Client)
+
+	item, _ := m.certificates.lookup(ctx, accountHash, certificateID, func() (map[int]interface{}, error) {
+		certificateList, err := cs.List(ctx, accountHash)
+		if err != nil {
+			return nil, err
+		}
+		items := map[int]interface{}{}
+		for i := range certificateList.List {
+			items[certificateList.List[i].ID] = &certificateList.List[i]
+		}
+		return items, nil
+	})
+	if cert, ok := item.(*models.Certificate); ok && cert != nil {
+		return cert, nil
+	}
+
+	ctx = withStatusRecorder(ctx)
+	cert, err := cs.Get(ctx, accountHash, certificateID)
+	if err := lookupError(ctx, err, cert != nil, "certificate", strconv.Itoa(certificateID)); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}